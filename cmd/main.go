@@ -2,46 +2,92 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"text/template"
 	"time"
 
 	"github.com/whatcher1074/stockspotlight/internal/api"
+	"github.com/whatcher1074/stockspotlight/internal/audit"
+	"github.com/whatcher1074/stockspotlight/internal/buildinfo"
 	"github.com/whatcher1074/stockspotlight/internal/cache"
 	"github.com/whatcher1074/stockspotlight/internal/config"
-	"github.com/whatcher1074/stockspotlight/internal/health"
 	"github.com/whatcher1074/stockspotlight/internal/logger"
+	"github.com/whatcher1074/stockspotlight/internal/server"
+	"github.com/whatcher1074/stockspotlight/internal/tracing"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
 	// finnhub "github.com/Finnhub-Stock-API/finnhub-go/v2"
 )
 
-var (
-	indexTemplate          *template.Template
-	stockTableTemplate     *template.Template
-	gainersTableTemplate   *template.Template
-	losersTableTemplate    *template.Template
-	companyProfileTemplate *template.Template
-	newsFeedTemplate       *template.Template
-)
+const defaultConfigPath = "internal/config/app.yaml"
 
 func main() {
+	startTime := time.Now()
+
+	// Config subcommands: stockspotlight config validate|print|hash-password
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+
+	fs := flag.NewFlagSet("stockspotlight", flag.ExitOnError)
+	configPath, overrides := configFlags(fs)
+	fs.Parse(os.Args[1:])
+
 	// Load config
-	cfg, err := config.Load("internal/config/app.yaml")
+	cfg, err := config.LoadWithFlags(*configPath, overrides)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	if err := run(config.NewStore(*configPath, cfg), startTime); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run starts the server and blocks until it has shut down. A returned error
+// has already been logged; the deferred cleanup has run either way, so sink
+// buffers, rotated file compressions, traces and the audit log are flushed.
+func run(cfgStore *config.Store, startTime time.Time) (err error) {
+	cfg := cfgStore.Current()
+
 	// Initialize logger
-	appLogger, err := logger.New("logs/app.log")
+	logLevel, _ := logger.ParseLevel(cfg.Logging.Level)
+	appLogger, err := logger.New(cfg.Logging.Path, logger.Options{
+		Format:   cfg.Logging.Format,
+		Level:    logLevel,
+		Compress: cfg.Logging.Compress,
+		Limits: logger.RotationLimits{
+			MaxSize:      int64(cfg.Logging.MaxSizeMB) * 1024 * 1024,
+			MaxAge:       time.Duration(cfg.Logging.MaxAgeDays) * 24 * time.Hour,
+			MaxFiles:     cfg.Logging.MaxFiles,
+			MaxTotalSize: int64(cfg.Logging.MaxTotalSizeMB) * 1024 * 1024,
+		},
+		Sinks:        logSinks(cfg.Logging.Sinks),
+		Secrets:      logSecrets(cfg),
+		DedupeWindow: cfg.Logging.DedupeWindow.Duration,
+		Sampling: logger.Sampling{
+			Initial:    cfg.Logging.Sampling.Initial,
+			Thereafter: cfg.Logging.Sampling.Thereafter,
+			Interval:   cfg.Logging.Sampling.Interval.Duration,
+		},
+	})
 	if err != nil {
-		log.Fatalf("Failed to initialize logger: %v", err)
+		log.Printf("Failed to initialize logger: %v", err)
+		return err
 	}
-	appLogger.Info("App starting...")
+	build := buildinfo.Get()
+	appLogger.Info("App starting...", "version", build.Version, "commit", build.Commit, "build_time", build.BuildTime, "commit_time", build.CommitTime)
 
 	// Defer logger cleanup
 	defer func() {
@@ -50,409 +96,287 @@ func main() {
 			log.Printf("Error closing logger: %v", err)
 		}
 	}()
+	// Log the error that ends run before the logger closes
+	defer func() {
+		if err != nil {
+			appLogger.Error(err.Error(), "fatal", true)
+		}
+	}()
+
+	// Set up tracing; spans are created (and trace IDs logged) even when
+	// nothing is exported
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: "stockspotlight",
+		Version:     build.Version,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			appLogger.Errorf("Error flushing traces: %v", err)
+		}
+	}()
+
+	// Open the audit log of admin actions
+	auditLog, err := audit.Open(cfg.Audit.Dir, time.Duration(cfg.Audit.RetentionDays)*24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer auditLog.Close()
 
 	// Initialize Finnhub client
-	api.InitFinnhubClient(cfg.APIKey)
+	api.InitFinnhubClient(cfg.Provider.APIKey.Value(), cfg.RateLimit.RequestsPerMinute)
 
 	// Load HTML templates
-	indexTemplate = template.Must(template.ParseFiles("static/index.html"))
-	stockTableTemplate = template.Must(template.ParseFiles("static/stock_table.html"))
-	gainersTableTemplate = template.Must(template.ParseFiles("static/gainers_table.html"))
-	losersTableTemplate = template.Must(template.ParseFiles("static/losers_table.html"))
-	companyProfileTemplate = template.Must(template.ParseFiles("static/company_profile.html"))
-	newsFeedTemplate = template.Must(template.ParseFiles("static/news_feed.html"))
+	templates, err := server.LoadTemplates("static")
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
 
 	// Setup cache
 	c := cache.New()
 
-	// Create a new ServeMux
-	mux := http.NewServeMux()
-
-	// Static assets (Bootstrap, etc.)
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
-
-	// Health check
-	mux.HandleFunc("/healthz", serveHealthz)
-
-	// Log management endpoints
-	mux.HandleFunc("/logs/status", func(w http.ResponseWriter, r *http.Request) {
-		stats, err := appLogger.GetStats()
-		if err != nil {
-			appLogger.Errorf("Error getting log stats: %v", err)
-			http.Error(w, fmt.Sprintf("Error getting log stats: %v", err), http.StatusInternalServerError)
-			return
+	// Apply reloaded settings that live outside the config store
+	cfgStore.OnReload(func(old, new *config.Config) {
+		if new.RateLimit.RequestsPerMinute != old.RateLimit.RequestsPerMinute {
+			api.SetRateLimit(new.RateLimit.RequestsPerMinute)
+		}
+		if new.Logging.Level != old.Logging.Level {
+			if level, err := logger.ParseLevel(new.Logging.Level); err == nil {
+				appLogger.SetLevel(level)
+			}
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		response := fmt.Sprintf(`{
-			"currentSize": "%s",
-			"currentAge": "%v",
-			"rotatedFiles": %d,
-			"totalSize": "%s",
-			"maxSize": "%s",
-			"maxAge": "%v",
-			"maxFiles": %d,
-			"status": "healthy"
-		}`,
-			stats.FormatSize(stats.CurrentSize),
-			stats.CurrentAge.Round(time.Minute),
-			stats.RotatedCount,
-			stats.FormatSize(stats.TotalSize),
-			stats.FormatSize(logger.MaxLogFileSize),
-			logger.MaxLogAge,
-			logger.MaxLogFiles,
-		)
-		w.Write([]byte(response))
-		appLogger.Infof("Log status requested: %s", stats.String())
 	})
 
-	mux.HandleFunc("/logs/rotate", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
+	// Watch the config file (and SIGHUP) for changes
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go cfgStore.Watch(watchCtx, 5*time.Second, func(result config.ReloadResult, err error) {
+		ev := audit.Event{Actor: audit.System, Action: "config.reload", Outcome: audit.OutcomeSuccess}
+		if err != nil {
+			ev.Outcome, ev.Error = audit.OutcomeFailure, err.Error()
+		} else if len(result.Applied) > 0 {
+			ev.Details = map[string]any{"applied": result.Applied}
+		}
+		if auditErr := auditLog.Record(ev); auditErr != nil {
+			appLogger.Errorf("Failed to write audit event: %v", auditErr)
 		}
 
-		appLogger.Info("Manual log rotation requested via API")
-		err := appLogger.ForceRotate()
 		if err != nil {
-			appLogger.Errorf("Error rotating logs: %v", err)
-			http.Error(w, fmt.Sprintf("Error rotating logs: %v", err), http.StatusInternalServerError)
+			appLogger.Errorf("Config reload rejected, keeping running config: %v", err)
 			return
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"status": "success", "message": "Log rotation completed"}`))
-		appLogger.Info("Manual log rotation completed successfully")
-	})
-
-	mux.HandleFunc("/logs/cleanup", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		if len(result.Applied) == 0 && len(result.RequiresRestart) == 0 {
+			appLogger.Info("Config reloaded, no changes")
 			return
 		}
-
-		appLogger.Info("Manual log cleanup requested via API")
-		err := appLogger.CleanupOldLogs()
-		if err != nil {
-			appLogger.Errorf("Error cleaning up logs: %v", err)
-			http.Error(w, fmt.Sprintf("Error cleaning up logs: %v", err), http.StatusInternalServerError)
-			return
+		for _, change := range result.Applied {
+			appLogger.Infof("Config reloaded: %s", change)
+		}
+		for _, change := range result.RequiresRestart {
+			appLogger.Warnf("Config change to %s requires a restart, ignored", change.Key)
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"status": "success", "message": "Log cleanup completed"}`))
-		appLogger.Info("Manual log cleanup completed successfully")
 	})
 
-	// Generic handler function for screener data (most active, gainers, losers)
-	createScreenerHandler := func(signal string, tmpl *template.Template, cacheKey string) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			appLogger.Infof("Request received for %s", signal)
-			now := time.Now().Format("15:04:05")
-
-			cachedData, found := c.Get(cacheKey)
-			var displayData []api.CombinedData
-
-			if found {
-				if d, ok := cachedData.([]api.CombinedData); ok {
-					displayData = d
-					appLogger.Infof("Using cached data for %s with %d items", signal, len(d))
-				} else {
-					appLogger.Errorf("Cached data type mismatch for %s", signal)
-					found = false
-				}
-			}
-
-			// If data not found in cache or expired, fetch it
-			if !found {
-				appLogger.Infof("Fetching fresh data for %s", signal)
-				data, err := api.FetchAllData(cfg.APIKey, cfg.TickerLimit, appLogger, signal)
-				if err != nil {
-					appLogger.Errorf("Failed to fetch %s data: %v", signal, err)
-					pageData := map[string]interface{}{
-						"HasData":   false,
-						"ErrorMsg":  fmt.Sprintf("Failed to load %s data: %v", signal, err),
-						"Timestamp": now,
-					}
-					tmpl.Execute(w, pageData)
-					return
-				}
-				c.Set(cacheKey, data, time.Duration(cfg.CacheTTL)*time.Second)
-				displayData = data
-				appLogger.Infof("Fetched %d items for %s, cached for %d seconds", len(data), signal, cfg.CacheTTL)
-			}
+	// Route the standard library's default logger (and slog.Default) through appLogger
+	slog.SetDefault(appLogger.Slog())
+
+	// Dashboard, data fragments, health checks and admin endpoints
+	srv := server.New(server.Options{
+		Config:    cfgStore,
+		Provider:  api.Finnhub{APIKey: cfg.Provider.APIKey.Value()},
+		Cache:     c,
+		Logger:    appLogger,
+		Audit:     auditLog,
+		Templates: templates,
+		StaticDir: "static",
+		Started:   startTime,
+	})
 
-			pageData := map[string]interface{}{
-				"Data":      displayData,
-				"HasData":   len(displayData) > 0,
-				"ErrorMsg":  "",
-				"Timestamp": now,
-			}
+	// Serve until SIGINT or SIGTERM, then shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := srv.Run(ctx); err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+	return nil
+}
 
-			err := tmpl.Execute(w, pageData)
-			if err != nil {
-				appLogger.Errorf("Template render failed for %s: %v", signal, err)
-			}
+// logSinks starts the remote log sinks described by the config.
+func logSinks(cfgs []config.SinkConfig) []logger.Sink {
+	var sinks []logger.Sink
+	for _, c := range cfgs {
+		switch c.Type {
+		case "syslog":
+			sinks = append(sinks, logger.NewSyslogSink(logger.SyslogConfig{
+				Name:       c.Name,
+				Network:    c.Network,
+				Address:    c.Address,
+				AppName:    c.AppName,
+				Facility:   c.Facility,
+				BufferSize: c.BufferSize,
+			}))
+		case "http":
+			sinks = append(sinks, logger.NewHTTPSink(logger.HTTPSinkConfig{
+				Name:          c.Name,
+				URL:           c.URL,
+				Authorization: c.Authorization.Value(),
+				BatchSize:     c.BatchSize,
+				FlushInterval: c.FlushInterval.Duration,
+				MaxRetries:    c.MaxRetries,
+				Timeout:       c.Timeout.Duration,
+				BufferSize:    c.BufferSize,
+			}))
 		}
 	}
+	return sinks
+}
 
-	// Stock screener endpoints
-	mux.HandleFunc("/data/most-active", createScreenerHandler("most_active", stockTableTemplate, "most_active_snapshot"))
-	mux.HandleFunc("/data/gainers", createScreenerHandler("gainers", gainersTableTemplate, "gainers_snapshot"))
-	mux.HandleFunc("/data/losers", createScreenerHandler("losers", losersTableTemplate, "losers_snapshot"))
+// logSecrets lists the configured secret values the logger must mask.
+func logSecrets(cfg *config.Config) []string {
+	secrets := []string{cfg.Provider.APIKey.Value()}
+	for _, sink := range cfg.Logging.Sinks {
+		secrets = append(secrets, sink.Authorization.Value())
+	}
+	for _, t := range cfg.Admin.Auth.Tokens {
+		secrets = append(secrets, t.Token.Value())
+	}
+	return secrets
+}
 
-	// Company Profile endpoint
-	mux.HandleFunc("/data/profile", func(w http.ResponseWriter, r *http.Request) {
-		symbol := r.URL.Query().Get("symbol")
-		if symbol == "" {
-			symbol = "AAPL" // Default to Apple
-		}
-		
-		appLogger.Infof("Request received for company profile: %s", symbol)
-		now := time.Now().Format("15:04:05")
-		cacheKey := fmt.Sprintf("profile_%s", symbol)
-
-		cachedData, found := c.Get(cacheKey)
-		var profileData map[string]interface{}
-
-		if found {
-			if d, ok := cachedData.(map[string]interface{}); ok {
-				profileData = d
-				appLogger.Infof("Using cached profile data for %s", symbol)
-			} else {
-				found = false
-			}
-		}
-		
-		if !found {
-			appLogger.Infof("Fetching fresh profile data for %s", symbol)
-			
-			// Mock profile data based on symbol
-			profiles := map[string]map[string]interface{}{
-				"AAPL": {
-					"Name":     "Apple Inc.",
-					"Industry": "Technology Hardware, Storage & Peripherals",
-					"WebURL":   "https://www.apple.com",
-					"Logo":     "https://logo.clearbit.com/apple.com",
-				},
-				"MSFT": {
-					"Name":     "Microsoft Corporation",
-					"Industry": "Systems Software",
-					"WebURL":   "https://www.microsoft.com",
-					"Logo":     "https://logo.clearbit.com/microsoft.com",
-				},
-				"GOOGL": {
-					"Name":     "Alphabet Inc.",
-					"Industry": "Interactive Media & Services",
-					"WebURL":   "https://www.google.com",
-					"Logo":     "https://logo.clearbit.com/google.com",
-				},
-				"TSLA": {
-					"Name":     "Tesla, Inc.",
-					"Industry": "Automobiles",
-					"WebURL":   "https://www.tesla.com",
-					"Logo":     "https://logo.clearbit.com/tesla.com",
-				},
-			}
-			
-			if profile, exists := profiles[symbol]; exists {
-				profileData = profile
-			} else {
-				profileData = map[string]interface{}{
-					"Name":     fmt.Sprintf("%s Corporation", symbol),
-					"Industry": "Technology",
-					"WebURL":   fmt.Sprintf("https://www.%s.com", symbol),
-					"Logo":     fmt.Sprintf("https://logo.clearbit.com/%s.com", symbol),
-				}
-			}
-			
-			c.Set(cacheKey, profileData, time.Duration(cfg.CacheTTL)*time.Second)
-			appLogger.Infof("Cached profile data for %s", symbol)
-		}
+// settingFlags collects repeated -set key=value flags.
+type settingFlags map[string]string
 
-		// Determine exchange based on symbol (mock logic)
-		exchange := "NASDAQ"
-		if len(symbol) > 0 && (symbol[0] >= 'A' && symbol[0] <= 'M') {
-			exchange = "NYSE"
-		}
+func (s settingFlags) String() string {
+	return fmt.Sprint(map[string]string(s))
+}
 
-		pageData := map[string]interface{}{
-			"Data":      profileData,
-			"HasData":   profileData != nil,
-			"ErrorMsg":  "",
-			"Timestamp": now,
-			"Name":      profileData["Name"],
-			"Ticker":    symbol,
-			"Exchange":  exchange,
-			"Industry":  profileData["Industry"],
-			"WebURL":    profileData["WebURL"],
-			"Logo":      profileData["Logo"],
-		}
+func (s settingFlags) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", v)
+	}
+	s[key] = value
+	return nil
+}
 
-		err := companyProfileTemplate.Execute(w, pageData)
-		if err != nil {
-			appLogger.Errorf("Template render failed for profile: %v", err)
-		}
+// configFlags registers the flags that select and override the config file.
+func configFlags(fs *flag.FlagSet) (*string, settingFlags) {
+	path := fs.String("config", defaultConfigPath, "path to the YAML config file")
+	overrides := settingFlags{}
+	fs.Var(overrides, "set", "override a setting, e.g. -set cache.ttl=30s (repeatable)")
+	fs.Func("port", "HTTP port (same as -set server.port=N)", func(v string) error {
+		overrides["server.port"] = v
+		return nil
 	})
+	fs.Func("log-level", "minimum log level (same as -set logging.level=L)", func(v string) error {
+		overrides["logging.level"] = v
+		return nil
+	})
+	return path, overrides
+}
 
-	// News Feed endpoint
-	mux.HandleFunc("/data/news", func(w http.ResponseWriter, r *http.Request) {
-		category := r.URL.Query().Get("category")
-		if category == "" {
-			category = "general"
-		}
-		
-		appLogger.Infof("Request received for news: %s", category)
-		now := time.Now().Format("15:04:05")
-		cacheKey := fmt.Sprintf("news_%s", category)
-
-		cachedData, found := c.Get(cacheKey)
-		var displayData []map[string]interface{}
-
-		if found {
-			if d, ok := cachedData.([]map[string]interface{}); ok {
-				displayData = d
-				appLogger.Infof("Using cached news data for %s with %d articles", category, len(d))
-			} else {
-				found = false
-			}
-		}
+// runConfigCommand implements `stockspotlight config validate|print|hash-password` and
+// returns the process exit code.
+func runConfigCommand(args []string) int {
+	const usage = "usage: stockspotlight config <validate|print|hash-password> [-config path] [-set key=value] [-format yaml|json]"
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	if args[0] == "hash-password" {
+		return hashPassword(os.Stdin)
+	}
 
-		if !found {
-			appLogger.Infof("Fetching fresh news data for %s", category)
-			
-			// Mock news data based on category
-			newsData := map[string][]map[string]interface{}{
-				"general": {
-					{
-						"Headline": "Stock Market Reaches New Highs Amid Economic Optimism",
-						"URL":      "https://example.com/news1",
-						"Source":   "Financial Times",
-						"Time":     time.Now().Format("Jan 2, 2006 15:04 MST"),
-					},
-					{
-						"Headline": "Federal Reserve Maintains Interest Rates",
-						"URL":      "https://example.com/news3",
-						"Source":   "Bloomberg",
-						"Time":     time.Now().Add(-2*time.Hour).Format("Jan 2, 2006 15:04 MST"),
-					},
-					{
-						"Headline": "Global Markets Show Strong Recovery Signs",
-						"URL":      "https://example.com/news4",
-						"Source":   "Reuters",
-						"Time":     time.Now().Add(-3*time.Hour).Format("Jan 2, 2006 15:04 MST"),
-					},
-				},
-				"tech": {
-					{
-						"Headline": "Tech Giants Report Strong Quarterly Earnings",
-						"URL":      "https://example.com/tech1", 
-						"Source":   "TechCrunch",
-						"Time":     time.Now().Add(-1*time.Hour).Format("Jan 2, 2006 15:04 MST"),
-					},
-					{
-						"Headline": "AI Innovation Drives Tech Sector Growth",
-						"URL":      "https://example.com/tech2",
-						"Source":   "Wired",
-						"Time":     time.Now().Add(-2*time.Hour).Format("Jan 2, 2006 15:04 MST"),
-					},
-					{
-						"Headline": "Cloud Computing Revenue Surges 40%",
-						"URL":      "https://example.com/tech3",
-						"Source":   "Ars Technica",
-						"Time":     time.Now().Add(-4*time.Hour).Format("Jan 2, 2006 15:04 MST"),
-					},
-				},
-				"finance": {
-					{
-						"Headline": "Banking Sector Shows Resilience in Q4",
-						"URL":      "https://example.com/fin1",
-						"Source":   "Wall Street Journal",
-						"Time":     time.Now().Add(-30*time.Minute).Format("Jan 2, 2006 15:04 MST"),
-					},
-					{
-						"Headline": "Cryptocurrency Market Volatility Continues",
-						"URL":      "https://example.com/fin2",
-						"Source":   "CoinDesk",
-						"Time":     time.Now().Add(-1*time.Hour).Format("Jan 2, 2006 15:04 MST"),
-					},
-					{
-						"Headline": "Corporate Bond Yields Rise Amid Inflation Concerns",
-						"URL":      "https://example.com/fin3",
-						"Source":   "Financial Times",
-						"Time":     time.Now().Add(-3*time.Hour).Format("Jan 2, 2006 15:04 MST"),
-					},
-				},
-			}
-			
-			if articles, exists := newsData[category]; exists {
-				displayData = articles
-			} else {
-				displayData = newsData["general"] // Fallback to general
-			}
-			
-			c.Set(cacheKey, displayData, time.Duration(cfg.CacheTTL)*time.Second)
-			appLogger.Infof("Cached %d news articles for %s", len(displayData), category)
-		}
+	fs := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+	configPath, overrides := configFlags(fs)
+	format := fs.String("format", "yaml", "output format for print: yaml or json")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
 
-		pageData := map[string]interface{}{
-			"Data":      displayData,
-			"HasData":   len(displayData) > 0,
-			"ErrorMsg":  "",
-			"Timestamp": now,
-		}
+	cfg, err := config.LoadWithFlags(*configPath, overrides)
 
-		err := newsFeedTemplate.Execute(w, pageData)
+	switch args[0] {
+	case "validate":
 		if err != nil {
-			appLogger.Errorf("Template render failed for news: %v", err)
+			printConfigError(*configPath, err)
+			return 1
 		}
-	})
+		fmt.Printf("%s: OK\n", *configPath)
+		return 0
 
-	// UI entry point
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		appLogger.Info("Serving UI /")
-		err := indexTemplate.Execute(w, nil)
+	case "print":
 		if err != nil {
-			appLogger.Errorf("Index template render failed: %v", err)
+			printConfigError(*configPath, err)
+			return 1
 		}
-	})
+		view := cfg.View()
+		switch *format {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(view)
+		case "yaml":
+			var out []byte
+			if out, err = yaml.Marshal(view); err == nil {
+				_, err = os.Stdout.Write(out)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "unknown format %q\n%s\n", *format, usage)
+			return 2
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print config: %v\n", err)
+			return 1
+		}
+		return 0
 
-	// Configure server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
 	}
+}
 
-	server := &http.Server{
-		Addr:    ":" + port,
-		Handler: mux,
+// hashPassword reads a password from the first line of in and prints its
+// bcrypt hash for admin.auth.users[].password_hash.
+func hashPassword(in io.Reader) int {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		fmt.Fprintf(os.Stderr, "failed to read password: %v\n", err)
+		return 1
 	}
-
-	// Start server in a goroutine
-	go func() {
-		appLogger.Infof("Server running at http://localhost:%s", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Could not listen on %s: %v\n", port, err)
-		}
-	}()
-
-	// Graceful shutdown
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
-
-	appLogger.Info("Shutting down server...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		appLogger.Fatalf("Server shutdown failed: %v", err)
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		fmt.Fprintln(os.Stderr, "usage: echo -n 'password' | stockspotlight config hash-password")
+		return 2
 	}
-
-	appLogger.Info("Server gracefully stopped")
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to hash password: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(hash))
+	return 0
 }
 
-func serveHealthz(w http.ResponseWriter, r *http.Request) {
-	health.Handler(w, r)
+// printConfigError writes a config error to stderr, one validation problem per line.
+func printConfigError(path string, err error) {
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d problem(s):\n", path, len(verr.Problems))
+	for _, p := range verr.Problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", p)
+	}
 }
@@ -8,25 +8,47 @@ import (
 	finnhub "github.com/Finnhub-Stock-API/finnhub-go/v2"
 	"github.com/whatcher1074/stockspotlight/internal/finnhub_limiter"
 	"github.com/whatcher1074/stockspotlight/internal/logger"
+	"github.com/whatcher1074/stockspotlight/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	cacheKey = "snapshot"
 )
 
-// Global rate limiter for Finnhub API calls (60 calls/minute = 1 call/second)
+// Global rate limiter for Finnhub API calls (60 calls/minute = 1 call/second
+// until SetRateLimit applies the configured rate)
 var finnhubLimiter = finnhub_limiter.NewLimiter(time.Second)
 
 // Finnhub API client
 var finnhubClient *finnhub.DefaultApiService
 
-// InitFinnhubClient initializes the Finnhub API client.
-func InitFinnhubClient(apiKey string) {
+var tracer = tracing.Tracer("internal/api")
+
+// startSpan starts a client span for a call to a provider endpoint.
+func startSpan(ctx context.Context, endpoint string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("provider", providerName), attribute.String("endpoint", endpoint))
+	return tracer.Start(ctx, providerName+" "+endpoint, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// InitFinnhubClient initializes the Finnhub API client and throttles it to
+// requestsPerMinute calls.
+func InitFinnhubClient(apiKey string, requestsPerMinute int) {
+	SetRateLimit(requestsPerMinute)
+
 	cfg := finnhub.NewConfiguration()
 	cfg.AddDefaultHeader("X-Finnhub-Token", apiKey)
 	finnhubClient = finnhub.NewAPIClient(cfg).DefaultApi
 }
 
+// SetRateLimit throttles Finnhub API calls to requestsPerMinute.
+func SetRateLimit(requestsPerMinute int) {
+	if requestsPerMinute > 0 {
+		finnhubLimiter.SetInterval(time.Minute / time.Duration(requestsPerMinute))
+	}
+}
+
 // CombinedData is the final data structure we'll cache.
 type CombinedData struct {
 	Ticker string
@@ -52,10 +74,14 @@ type NewsArticle struct {
 	Time     string // Formatted time for display
 }
 
-// FetchAllData fetches data for a given screener signal.
+// FetchAllData fetches data for a given screener signal, restricted to the
+// tickers universe and at most limit rows.
 // The finnhub-go library version being used does not have the StockScreener function.
 // This is a mock function to allow the application to run.
-func FetchAllData(apiKey string, limit int, logger *logger.Logger, signal string) ([]CombinedData, error) {
+func FetchAllData(ctx context.Context, apiKey string, tickers []string, limit int, signal string) ([]CombinedData, error) {
+	ctx, span := startSpan(ctx, "screener", attribute.String("signal", signal))
+	defer span.End()
+
 	// Mock data
 	mockData := []CombinedData{
 		{Ticker: "AAPL", Name: "Apple Inc.", Price: 172.28, High: 173.05, Low: 170.12, Volume: 52, Change: -0.54},
@@ -65,15 +91,39 @@ func FetchAllData(apiKey string, limit int, logger *logger.Logger, signal string
 		{Ticker: "TSLA", Name: "Tesla, Inc.", Price: 234.86, High: 238.90, Low: 232.50, Volume: 60, Change: 2.50},
 	}
 
-	return mockData, nil
+	universe := make(map[string]bool, len(tickers))
+	for _, t := range tickers {
+		universe[t] = true
+	}
+
+	var data []CombinedData
+	for _, d := range mockData {
+		if len(data) >= limit {
+			break
+		}
+		if universe[d.Ticker] {
+			data = append(data, d)
+		}
+	}
+
+	logger.FromContext(ctx).Debug("Screener data assembled", logger.Signal(signal), "items", len(data))
+	return data, nil
 }
 
 // FetchCompanyProfile fetches the company profile for a given symbol.
-func FetchCompanyProfile(symbol string) (finnhub.CompanyProfile2, error) {
-	finnhubLimiter.Wait() // Wait before making the API call
-	ctx := context.Background()
+func FetchCompanyProfile(ctx context.Context, symbol string) (_ finnhub.CompanyProfile2, err error) {
+	ctx, span := startSpan(ctx, "company_profile2", attribute.String("symbol", symbol))
+	defer func() { tracing.End(span, err) }()
 
-	profile, _, err := finnhubClient.CompanyProfile2(ctx).Symbol(symbol).Execute()
+	// Wait before making the API call
+	if err := finnhubLimiter.Wait(ctx); err != nil {
+		return finnhub.CompanyProfile2{}, fmt.Errorf("waiting to fetch company profile for %s: %w", symbol, err)
+	}
+	logger.FromContext(ctx).Debug("Calling Finnhub", "endpoint", "company_profile2", logger.Symbol(symbol))
+
+	start := time.Now()
+	profile, resp, err := finnhubClient.CompanyProfile2(ctx).Symbol(symbol).Execute()
+	recordUpstream("company_profile2", start, resp, err)
 	if err != nil {
 		return finnhub.CompanyProfile2{}, fmt.Errorf("failed to fetch company profile for %s: %w", symbol, err)
 	}
@@ -82,14 +132,22 @@ func FetchCompanyProfile(symbol string) (finnhub.CompanyProfile2, error) {
 }
 
 // FetchNews fetches general news articles.
-func FetchNews(category string, limit int) ([]NewsArticle, error) {
-	finnhubLimiter.Wait() // Wait before making the API call
-	ctx := context.Background()
+func FetchNews(ctx context.Context, category string, limit int) (_ []NewsArticle, err error) {
+	ctx, span := startSpan(ctx, "company_news", attribute.String("category", category))
+	defer func() { tracing.End(span, err) }()
+
+	// Wait before making the API call
+	if err := finnhubLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("waiting to fetch news: %w", err)
+	}
+	logger.FromContext(ctx).Debug("Calling Finnhub", "endpoint", "company_news", "category", category)
 
 	// The API now uses CompanyNews instead of News and requires From and To dates.
 	// For simplicity, I'm using a fixed date range for now.
 	// You might want to make these parameters dynamic based on your application's needs.
-	news, _, err := finnhubClient.CompanyNews(ctx).Symbol("AAPL").From("2023-01-01").To("2023-01-01").Execute()
+	start := time.Now()
+	news, resp, err := finnhubClient.CompanyNews(ctx).Symbol("AAPL").From("2023-01-01").To("2023-01-01").Execute()
+	recordUpstream("company_news", start, resp, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch news for %s: %w", category, err)
 	}
@@ -99,16 +157,18 @@ func FetchNews(category string, limit int) ([]NewsArticle, error) {
 		if i >= limit {
 			break
 		}
+		// Every field is optional in the response; the getters return zero
+		// values for missing ones
 		articles = append(articles, NewsArticle{
-			Category: *article.Category,
-			Datetime: *article.Datetime,
-			Headline: *article.Headline,
-			ID:       *article.Id,
-			Image:    *article.Image,
-			Related:  *article.Related,
-			Source:   *article.Source,
-			Summary:  *article.Summary,
-			URL:      *article.Url,
+			Category: article.GetCategory(),
+			Datetime: article.GetDatetime(),
+			Headline: article.GetHeadline(),
+			ID:       article.GetId(),
+			Image:    article.GetImage(),
+			Related:  article.GetRelated(),
+			Source:   article.GetSource(),
+			Summary:  article.GetSummary(),
+			URL:      article.GetUrl(),
 		})
 	}
 
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// providerName labels upstream metrics.
+const providerName = "finnhub"
+
+var (
+	upstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "upstream",
+		Name:      "requests_total",
+		Help:      "Calls to the market data provider by provider and endpoint.",
+	}, []string{"provider", "endpoint"})
+
+	upstreamErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "upstream",
+		Name:      "errors_total",
+		Help:      "Failed calls to the market data provider by provider and endpoint.",
+	}, []string{"provider", "endpoint"})
+
+	upstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "upstream",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of calls to the market data provider, excluding limiter waits.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "endpoint"})
+)
@@ -0,0 +1,20 @@
+package api
+
+import "context"
+
+// Finnhub is the Finnhub provider as a value, for code that takes its
+// provider as a dependency. InitFinnhubClient must have been called.
+type Finnhub struct {
+	APIKey string
+}
+
+// FetchAllData fetches screener rows for signal; see the package-level
+// FetchAllData.
+func (f Finnhub) FetchAllData(ctx context.Context, tickers []string, limit int, signal string) ([]CombinedData, error) {
+	return FetchAllData(ctx, f.APIKey, tickers, limit, signal)
+}
+
+// Ping checks that the provider is reachable; see the package-level Ping.
+func (Finnhub) Ping(ctx context.Context) error {
+	return Ping(ctx)
+}
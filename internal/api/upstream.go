@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/tracing"
+)
+
+// maxTrackedCalls bounds how many recent upstream outcomes are kept.
+const maxTrackedCalls = 200
+
+// upstream records recent provider call outcomes and the quota the provider
+// last reported, for health checks.
+var upstream upstreamStats
+
+type callOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+type upstreamStats struct {
+	mu    sync.Mutex
+	calls []callOutcome // oldest first, at most maxTrackedCalls
+	quota Quota
+}
+
+// Quota is the rate limit the provider reported on its last response.
+type Quota struct {
+	Known     bool      `json:"known"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// recordUpstream notes the outcome of a call to endpoint that started at
+// start, and any quota headers on its response. Every request sent to the
+// provider must be recorded here. The screener data is still mocked and
+// sends none, and the profile and news widgets do not call the provider
+// yet, so for now the figures come from Ping alone.
+func recordUpstream(endpoint string, start time.Time, resp *http.Response, err error) {
+	upstreamRequests.WithLabelValues(providerName, endpoint).Inc()
+	upstreamDuration.WithLabelValues(providerName, endpoint).Observe(time.Since(start).Seconds())
+	if err != nil {
+		upstreamErrors.WithLabelValues(providerName, endpoint).Inc()
+	}
+
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+
+	upstream.calls = append(upstream.calls, callOutcome{at: time.Now(), failed: err != nil})
+	if n := len(upstream.calls); n > maxTrackedCalls {
+		upstream.calls = append(upstream.calls[:0], upstream.calls[n-maxTrackedCalls:]...)
+	}
+
+	if resp == nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-Ratelimit-Remaining"))
+	if err != nil {
+		return
+	}
+	q := Quota{Known: true, Remaining: remaining}
+	q.Limit, _ = strconv.Atoi(resp.Header.Get("X-Ratelimit-Limit"))
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-Ratelimit-Reset"), 10, 64); err == nil {
+		q.Reset = time.Unix(reset, 0)
+	}
+	upstream.quota = q
+}
+
+// ErrorRate returns the fraction of provider calls in the last window that
+// failed, and how many calls that is based on.
+func ErrorRate(window time.Duration) (rate float64, calls int) {
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+
+	cutoff := time.Now().Add(-window)
+	failed := 0
+	for _, c := range upstream.calls {
+		if c.at.Before(cutoff) {
+			continue
+		}
+		calls++
+		if c.failed {
+			failed++
+		}
+	}
+	if calls == 0 {
+		return 0, 0
+	}
+	return float64(failed) / float64(calls), calls
+}
+
+// CurrentQuota returns the quota the provider last reported.
+func CurrentQuota() Quota {
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+	return upstream.quota
+}
+
+// Ping checks that the provider is reachable and accepts the API key, using
+// the cheap market status endpoint.
+func Ping(ctx context.Context) (err error) {
+	if finnhubClient == nil {
+		return fmt.Errorf("finnhub client not initialized")
+	}
+	ctx, span := startSpan(ctx, "market_status")
+	defer func() { tracing.End(span, err) }()
+
+	if err := finnhubLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting to ping finnhub: %w", err)
+	}
+	start := time.Now()
+	_, resp, err := finnhubClient.MarketStatus(ctx).Exchange("US").Execute()
+	recordUpstream("market_status", start, resp, err)
+	if err != nil {
+		return fmt.Errorf("finnhub market status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// File: internal/audit/audit.go
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded for an action.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// Anonymous is the actor recorded when a request carries no identity.
+const Anonymous = "anonymous"
+
+// System is the actor recorded for actions the server takes on its own,
+// such as reloading its config on SIGHUP.
+const System = "system"
+
+// Query pagination limits.
+const (
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 1000
+)
+
+// Event is one audited action: who did what, when, and how it went.
+type Event struct {
+	Time       time.Time      `json:"time"`
+	Actor      string         `json:"actor"`
+	RemoteAddr string         `json:"remote_addr,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	Action     string         `json:"action"`
+	Outcome    string         `json:"outcome"`
+	Error      string         `json:"error,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// Log is an append-only audit trail kept apart from the application log. It
+// writes one JSON event per line to a file per day, and deletes days older
+// than its retention. Events are never rewritten.
+type Log struct {
+	dir       string
+	retention time.Duration
+
+	mu     sync.Mutex
+	file   *os.File
+	day    string // date of the open file, "2006-01-02"
+	closed bool
+}
+
+const (
+	filePrefix = "audit-"
+	fileSuffix = ".jsonl"
+	dayLayout  = "2006-01-02"
+)
+
+// Open opens the audit log in dir, creating it if needed, and removes days
+// older than retention.
+func Open(dir string, retention time.Duration) (*Log, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %v", err)
+	}
+	l := &Log{dir: dir, retention: retention}
+	if err := l.cleanup(time.Now()); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record appends ev, filling in its time if unset and the anonymous actor if
+// it has none.
+func (l *Log) Record(ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC()
+	if ev.Actor == "" {
+		ev.Actor = Anonymous
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return fmt.Errorf("audit log is closed")
+	}
+
+	day := ev.Time.Format(dayLayout)
+	if l.file == nil || day != l.day {
+		if err := l.openDayLocked(day); err != nil {
+			return err
+		}
+		// A new day is also when older days fall out of retention
+		if err := l.cleanup(ev.Time); err != nil {
+			fmt.Printf("Warning: failed to clean up audit log: %v\n", err)
+		}
+	}
+	if _, err := l.file.Write(line); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+func (l *Log) openDayLocked(day string) error {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	path := filepath.Join(l.dir, filePrefix+day+fileSuffix)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	l.file, l.day = file, day
+	return nil
+}
+
+// files returns the audit files, oldest first, with the day each covers.
+func (l *Log) files() ([]string, []time.Time, error) {
+	matches, err := filepath.Glob(filepath.Join(l.dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(matches) // the date in the name sorts chronologically
+
+	var paths []string
+	var days []time.Time
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), filePrefix), fileSuffix)
+		day, err := time.Parse(dayLayout, name)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, m)
+		days = append(days, day)
+	}
+	return paths, days, nil
+}
+
+// cleanup deletes the files of days that ended more than retention before now.
+func (l *Log) cleanup(now time.Time) error {
+	if l.retention <= 0 {
+		return nil
+	}
+	paths, days, err := l.files()
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-l.retention)
+	for i, path := range paths {
+		if days[i].AddDate(0, 0, 1).Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the current file. Later calls to Record fail.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Filter selects events; zero fields match everything.
+type Filter struct {
+	Action  string // exact action, or a prefix ending in "." such as "logs."
+	Actor   string
+	Outcome string
+	From    time.Time
+	To      time.Time
+}
+
+// Match reports whether ev passes the filter.
+func (f Filter) Match(ev Event) bool {
+	if f.Action != "" {
+		if strings.HasSuffix(f.Action, ".") {
+			if !strings.HasPrefix(ev.Action, f.Action) {
+				return false
+			}
+		} else if ev.Action != f.Action {
+			return false
+		}
+	}
+	if f.Actor != "" && ev.Actor != f.Actor {
+		return false
+	}
+	if f.Outcome != "" && ev.Outcome != f.Outcome {
+		return false
+	}
+	if !f.From.IsZero() && ev.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && ev.Time.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// QueryResult is one page of matching events, oldest first.
+type QueryResult struct {
+	Events []Event `json:"events"`
+	// NextOffset is the offset of the next page, or -1 if there is none.
+	NextOffset int `json:"nextOffset"`
+}
+
+// Query returns the events matching f, skipping the first offset matches and
+// returning at most limit.
+func (l *Log) Query(f Filter, offset, limit int) (QueryResult, error) {
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	paths, days, err := l.files()
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	result := QueryResult{Events: []Event{}, NextOffset: -1}
+	matched := 0
+	for i, path := range paths {
+		// Skip whole days outside the time range; a day's events are
+		// before the next midnight
+		if !f.From.IsZero() && !days[i].AddDate(0, 0, 1).After(f.From) {
+			continue
+		}
+		if !f.To.IsZero() && days[i].After(f.To) {
+			break
+		}
+
+		done, err := scanFile(path, func(ev Event) bool {
+			if !f.Match(ev) {
+				return true
+			}
+			matched++
+			if matched <= offset {
+				return true
+			}
+			if len(result.Events) == limit {
+				result.NextOffset = offset + limit
+				return false
+			}
+			result.Events = append(result.Events, ev)
+			return true
+		})
+		if err != nil {
+			return result, err
+		}
+		if done {
+			break
+		}
+	}
+	return result, nil
+}
+
+// scanFile calls fn for each event in path until fn returns false, and
+// reports whether it stopped early. Lines that do not parse are skipped.
+func scanFile(path string, fn func(Event) bool) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil // removed by retention since it was listed
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev Event
+		if json.Unmarshal(scanner.Bytes(), &ev) != nil {
+			continue
+		}
+		if !fn(ev) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the authenticated identity of
+// the caller, recorded as the actor of its audit events.
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom returns the identity stored in ctx, or Anonymous.
+func IdentityFrom(ctx context.Context) string {
+	if id, ok := ctx.Value(identityKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Anonymous
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTestLog(t *testing.T, retention time.Duration) *Log {
+	t.Helper()
+	l, err := Open(t.TempDir(), retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func record(t *testing.T, l *Log, events ...Event) {
+	t.Helper()
+	for _, ev := range events {
+		if err := l.Record(ev); err != nil {
+			t.Fatalf("Record(%s): %v", ev.Action, err)
+		}
+	}
+}
+
+func actions(events []Event) []string {
+	out := []string{}
+	for _, ev := range events {
+		out = append(out, ev.Action)
+	}
+	return out
+}
+
+func TestRecordQueryRoundTrip(t *testing.T) {
+	l := openTestLog(t, 0)
+	when := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+	record(t, l, Event{
+		Time:       when,
+		RemoteAddr: "203.0.113.7",
+		RequestID:  "abc123",
+		Action:     "cache.clear",
+		Outcome:    OutcomeSuccess,
+		Details:    map[string]any{"prefix": "quote:", "removed": float64(3)},
+	})
+
+	got, err := l.Query(Filter{}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Event{
+		Time:       when.UTC(),
+		Actor:      Anonymous,
+		RemoteAddr: "203.0.113.7",
+		RequestID:  "abc123",
+		Action:     "cache.clear",
+		Outcome:    OutcomeSuccess,
+		Details:    map[string]any{"prefix": "quote:", "removed": float64(3)},
+	}
+	if len(got.Events) != 1 || !reflect.DeepEqual(got.Events[0], want) {
+		t.Errorf("Query() = %+v, want [%+v]", got.Events, want)
+	}
+	if got.NextOffset != -1 {
+		t.Errorf("NextOffset = %d, want -1", got.NextOffset)
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	l := openTestLog(t, 0)
+	record(t, l,
+		Event{Action: "logs.rotate", Actor: "ops", Outcome: OutcomeSuccess},
+		Event{Action: "logging.set_level", Actor: "ops", Outcome: OutcomeSuccess},
+		Event{Action: "logs.cleanup", Actor: "token:ci", Outcome: OutcomeFailure},
+		Event{Action: "admin.auth", Outcome: OutcomeDenied},
+	)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"everything", Filter{}, []string{"logs.rotate", "logging.set_level", "logs.cleanup", "admin.auth"}},
+		{"prefix", Filter{Action: "logs."}, []string{"logs.rotate", "logs.cleanup"}},
+		{"exact action", Filter{Action: "logs.rotate"}, []string{"logs.rotate"}},
+		{"prefix without dot is exact", Filter{Action: "logs"}, []string{}},
+		{"actor", Filter{Actor: "ops"}, []string{"logs.rotate", "logging.set_level"}},
+		{"anonymous actor", Filter{Actor: Anonymous}, []string{"admin.auth"}},
+		{"outcome", Filter{Outcome: OutcomeFailure}, []string{"logs.cleanup"}},
+		{"combined", Filter{Action: "logs.", Actor: "ops"}, []string{"logs.rotate"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Query(tt.filter, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actions(got.Events), tt.want) {
+				t.Errorf("Query() = %q, want %q", actions(got.Events), tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryPaging(t *testing.T) {
+	l := openTestLog(t, 0)
+	for _, a := range []string{"a", "b", "c", "d", "e"} {
+		record(t, l, Event{Action: a})
+	}
+
+	tests := []struct {
+		offset, limit int
+		want          []string
+		next          int
+	}{
+		{0, 2, []string{"a", "b"}, 2},
+		{2, 2, []string{"c", "d"}, 4},
+		{4, 2, []string{"e"}, -1},
+		{3, 2, []string{"d", "e"}, -1},
+		{5, 2, []string{}, -1},
+		{-1, 0, []string{"a", "b", "c", "d", "e"}, -1},
+	}
+	for _, tt := range tests {
+		got, err := l.Query(Filter{}, tt.offset, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actions(got.Events), tt.want) || got.NextOffset != tt.next {
+			t.Errorf("Query(offset %d, limit %d) = %q next %d, want %q next %d",
+				tt.offset, tt.limit, actions(got.Events), got.NextOffset, tt.want, tt.next)
+		}
+	}
+}
+
+func TestQueryTimeRangeSkipsOtherDays(t *testing.T) {
+	l := openTestLog(t, 0)
+	day := func(d, hour int) time.Time { return time.Date(2026, 5, d, hour, 0, 0, 0, time.UTC) }
+	record(t, l,
+		Event{Time: day(1, 12), Action: "day1"},
+		Event{Time: day(2, 8), Action: "day2.morning"},
+		Event{Time: day(2, 20), Action: "day2.evening"},
+		Event{Time: day(3, 12), Action: "day3"},
+	)
+	// Files of days outside the range must not be read at all: replace them
+	// with something that fails to scan
+	for _, d := range []string{"2026-05-01", "2026-05-03"} {
+		path := filepath.Join(l.dir, filePrefix+d+fileSuffix)
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(path, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := l.Query(Filter{From: day(2, 0), To: day(2, 12)}, 0, 0)
+	if err != nil {
+		t.Fatalf("Query() read a day outside the range: %v", err)
+	}
+	if want := []string{"day2.morning"}; !reflect.DeepEqual(actions(got.Events), want) {
+		t.Errorf("Query() = %q, want %q", actions(got.Events), want)
+	}
+}
+
+func TestRecordRemovesExpiredDaysOnRollover(t *testing.T) {
+	l := openTestLog(t, 48*time.Hour)
+	now := time.Now().UTC()
+	old := now.AddDate(0, 0, -10)
+	record(t, l, Event{Time: old, Action: "old"})
+	oldFile := filepath.Join(l.dir, filePrefix+old.Format(dayLayout)+fileSuffix)
+	if _, err := os.Stat(oldFile); err != nil {
+		t.Fatalf("old day was not written: %v", err)
+	}
+
+	record(t, l, Event{Time: now, Action: "new"})
+
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Errorf("day past retention still exists after rollover (stat error %v)", err)
+	}
+	got, err := l.Query(Filter{}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"new"}; !reflect.DeepEqual(actions(got.Events), want) {
+		t.Errorf("Query() = %q, want %q", actions(got.Events), want)
+	}
+}
+
+func TestRecordAfterClose(t *testing.T) {
+	l := openTestLog(t, 0)
+	record(t, l, Event{Action: "before"})
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(Event{Action: "after"}); err == nil {
+		t.Error("Record() after Close succeeded, want an error")
+	}
+}
@@ -0,0 +1,57 @@
+// File: internal/buildinfo/buildinfo.go
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/whatcher1074/stockspotlight/internal/buildinfo.Version=v1.2.0" ./cmd
+//
+// scripts/build.sh fills all three from git.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	// CommitTime is when Commit was made, from the VCS stamp. It is not the
+	// build time, which only the -ldflags above can set.
+	CommitTime string `json:"commitTime,omitempty"`
+	GoVersion  string `json:"goVersion"`
+}
+
+// Get returns the build metadata. A commit that was not injected, and the
+// commit time, are taken from the VCS stamp Go records in the binary, if any.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time":
+				info.CommitTime = s.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
@@ -1,10 +1,19 @@
 package cache
 
 import (
+	"context"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = tracing.Tracer("internal/cache")
+
 type cacheEntry struct {
 	data      interface{}
 	timestamp time.Time
@@ -35,24 +44,86 @@ func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
 }
 
 // Get retrieves a value if not expired
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+func (c *Cache) Get(ctx context.Context, key string) (value interface{}, found bool) {
+	_, span := tracer.Start(ctx, "cache.Get", trace.WithAttributes(attribute.String("cache.prefix", keyPrefix(key))))
+	defer func() {
+		span.SetAttributes(attribute.Bool("cache.hit", found))
+		span.End()
+	}()
 
+	c.mu.RLock()
 	entry, ok := c.data[key]
+	c.mu.RUnlock()
 	if !ok {
+		recordLookup(key, false)
 		return nil, false
 	}
 	if time.Since(entry.timestamp) > entry.ttl {
-		delete(c.data, key)
+		c.evictExpired(key)
+		recordLookup(key, false)
 		return nil, false
 	}
+	recordLookup(key, true)
 	return entry.data, true
 }
 
+// evictExpired removes key if it is still expired once the write lock is
+// held, since another caller may have Set it in the meantime.
+func (c *Cache) evictExpired(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.data[key]; ok && time.Since(entry.timestamp) > entry.ttl {
+		delete(c.data, key)
+	}
+}
+
 // Delete removes a key manually
 func (c *Cache) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	delete(c.data, key)
 }
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.data)
+}
+
+// Entry describes a cached key without its value.
+type Entry struct {
+	Key     string    `json:"key"`
+	Expires time.Time `json:"expires"`
+	Expired bool      `json:"expired"`
+}
+
+// Entries returns every key in the cache, sorted, including expired ones not
+// yet removed.
+func (c *Cache) Entries() []Entry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := time.Now()
+	entries := make([]Entry, 0, len(c.data))
+	for key, entry := range c.data {
+		expires := entry.timestamp.Add(entry.ttl)
+		entries = append(entries, Entry{Key: key, Expires: expires, Expired: now.After(expires)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// Clear removes every key starting with prefix, or every key if prefix is
+// empty, and returns how many were removed.
+func (c *Cache) Clear(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for key := range c.data {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.data, key)
+			n++
+		}
+	}
+	return n
+}
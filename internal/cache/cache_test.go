@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Run with -race: Get evicts expired entries while other callers Set them.
+func TestGetEvictsExpiredConcurrently(t *testing.T) {
+	c := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				c.Set("k", j, time.Nanosecond)
+				c.Get(context.Background(), "k")
+			}
+		}()
+	}
+	wg.Wait()
+}
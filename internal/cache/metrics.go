@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var lookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "stockspotlight",
+	Subsystem: "cache",
+	Name:      "lookups_total",
+	Help:      "Cache lookups by key prefix and result (hit or miss).",
+}, []string{"prefix", "result"})
+
+// keyPrefix returns the part of key before the first ":", which groups keys
+// such as "profile:AAPL" without a label per symbol.
+func keyPrefix(key string) string {
+	if i := strings.IndexByte(key, ':'); i > 0 {
+		return key[:i]
+	}
+	return "other"
+}
+
+func recordLookup(key string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	lookups.WithLabelValues(keyPrefix(key), result).Inc()
+}
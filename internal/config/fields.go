@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Field is a single leaf setting addressed by its dotted YAML path,
+// e.g. "cache.ttl".
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields flattens the config into its leaf settings in declaration order.
+func (c *Config) Fields() []Field {
+	var fields []Field
+	walkFields(reflect.ValueOf(c).Elem(), "", &fields)
+	return fields
+}
+
+var durationType = reflect.TypeOf(Duration{})
+
+func walkFields(v reflect.Value, prefix string, out *[]Field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" || sf.PkgPath != "" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != durationType {
+			walkFields(fv, key, out)
+			continue
+		}
+		*out = append(*out, Field{Key: key, Value: fv.Interface()})
+	}
+}
+
+// Change records a setting whose value differs between two configs.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Diff lists the settings that differ between old and new.
+func Diff(old, new *Config) []Change {
+	oldFields := old.Fields()
+	newFields := new.Fields()
+
+	var changes []Change
+	for i := range oldFields {
+		if reflect.DeepEqual(oldFields[i].Value, newFields[i].Value) {
+			continue
+		}
+		changes = append(changes, Change{
+			Key: oldFields[i].Key,
+			Old: fmt.Sprint(oldFields[i].Value),
+			New: fmt.Sprint(newFields[i].Value),
+		})
+	}
+	return changes
+}
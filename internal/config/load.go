@@ -1,36 +1,504 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/netip"
 	"os"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Config defines the app settings loaded from config/app.yaml
 type Config struct {
-	APIKey          string `yaml:"polygon_api_key"`
-	CacheTTL        int    `yaml:"cache_ttl_seconds"`
-	PollingInterval int    `yaml:"polling_interval_seconds"`
-	TickerLimit     int    `yaml:"ticker_limit"`
+	Server    ServerConfig    `yaml:"server"`
+	Admin     AdminConfig     `yaml:"admin"`
+	TLS       TLSConfig       `yaml:"tls"`
+	Provider  ProviderConfig  `yaml:"provider"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Dashboard DashboardConfig `yaml:"dashboard"`
+	Audit     AuditConfig     `yaml:"audit"`
+	Health    HealthConfig    `yaml:"health"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+
+	sources map[string]Source // keys not listed came from defaults
+	flags   map[string]string // command-line overrides, reapplied on reload
+}
+
+// ServerConfig holds the HTTP listener settings.
+type ServerConfig struct {
+	Port            int      `yaml:"port"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout"`
+	// Timeouts of the http.Server; 0 means none, except for
+	// ReadHeaderTimeout which is required. The log tail stream lifts the
+	// write timeout for itself.
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout"`
+	ReadTimeout       Duration `yaml:"read_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout"`
+	// TrustedProxies are the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header is believed when identifying clients.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// ParseTrustedProxies returns TrustedProxies as prefixes; a bare address is
+// a prefix of its full length.
+func (s ServerConfig) ParseTrustedProxies() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(s.TrustedProxies))
+	for _, p := range s.TrustedProxies {
+		if addr, err := netip.ParseAddr(p); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", p)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// AdminConfig holds the settings of the optional admin listener.
+type AdminConfig struct {
+	// Address is host:port or unix:/path/to.sock, localhost:9090 by
+	// default. The log, cache, config, quota and metrics endpoints are served
+	// on this listener, together with pprof and expvar. Empty moves them onto
+	// the public port, which requires Auth.
+	Address string `yaml:"address"`
+	// Auth protects the admin endpoints wherever they are served.
+	Auth AdminAuthConfig `yaml:"auth"`
+	// ClientCAFile, when set, makes a TCP admin listener require client
+	// certificates signed by one of the CAs in this PEM file. Needs tls.
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
+// TLSConfig enables HTTPS on the public port, and on a TCP admin listener.
+// The certificate and key are reloaded when either file changes.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// RedirectAddress, when set, is a host:port serving plain HTTP that
+	// redirects every request to HTTPS on server.port.
+	RedirectAddress string `yaml:"redirect_address"`
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval Duration `yaml:"reload_interval"`
+}
+
+// Enabled reports whether a certificate is configured.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// AdminAuthConfig lists who may use the admin endpoints. With no tokens and
+// no users configured, the endpoints are open, which is only allowed on a
+// separate admin listener.
+type AdminAuthConfig struct {
+	Tokens []AdminToken `yaml:"tokens"`
+	Users  []AdminUser  `yaml:"users"`
+	// MaxFailures failed attempts from one address within Lockout block
+	// further attempts from it until the window has passed.
+	MaxFailures int      `yaml:"max_failures"`
+	Lockout     Duration `yaml:"lockout"`
+}
+
+// AdminToken is a static bearer token. Name identifies its holder in the
+// audit log.
+type AdminToken struct {
+	Name  string `yaml:"name" json:"name"`
+	Token Secret `yaml:"token" json:"token"`
+}
+
+// AdminUser is an HTTP basic auth account with a bcrypt password hash, as
+// printed by `stockspotlight config hash-password`.
+type AdminUser struct {
+	Username     string `yaml:"username" json:"username"`
+	PasswordHash Secret `yaml:"password_hash" json:"password_hash"`
+}
+
+// Enabled reports whether any credentials are configured.
+func (a AdminAuthConfig) Enabled() bool {
+	return len(a.Tokens) > 0 || len(a.Users) > 0
+}
+
+// Listen returns the network and address to listen on for Address.
+func (a AdminConfig) Listen() (network, address string) {
+	if path, ok := strings.CutPrefix(a.Address, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", a.Address
+}
+
+// ProviderConfig selects the upstream market data provider. The API key is
+// read from the environment variable named by APIKeyEnv when it is set,
+// otherwise from exactly one of APIKey, APIKeyFile or APIKeyCommand.
+type ProviderConfig struct {
+	Name          string `yaml:"name"`
+	APIKey        Secret `yaml:"api_key"`
+	APIKeyEnv     string `yaml:"api_key_env"`
+	APIKeyFile    string `yaml:"api_key_file"`
+	APIKeyCommand string `yaml:"api_key_command"`
+}
+
+// CacheConfig holds the TTLs for cached widget data.
+type CacheConfig struct {
+	TTL        Duration `yaml:"ttl"`
+	ProfileTTL Duration `yaml:"profile_ttl"`
+	NewsTTL    Duration `yaml:"news_ttl"`
+}
+
+// LoggingConfig holds the application log settings.
+type LoggingConfig struct {
+	Level          string `yaml:"level"`
+	Format         string `yaml:"format"`
+	Path           string `yaml:"path"`
+	Compress       bool   `yaml:"compress"`
+	MaxSizeMB      int    `yaml:"max_size_mb"`
+	MaxAgeDays     int    `yaml:"max_age_days"`
+	MaxFiles       int    `yaml:"max_files"`
+	MaxTotalSizeMB int    `yaml:"max_total_size_mb"` // 0 means no cap
+	// DedupeWindow collapses repeats of a message into one summary; 0 disables.
+	DedupeWindow Duration       `yaml:"dedupe_window"`
+	Sampling     SamplingConfig `yaml:"sampling"`
+	// Sinks ship every record to remote destinations as well as the file.
+	Sinks []SinkConfig `yaml:"sinks"`
+}
+
+// SamplingConfig thins out hot call sites below warn: per interval, the first
+// Initial records from a call site are logged, then every Thereafter-th.
+// An Initial of 0 disables sampling.
+type SamplingConfig struct {
+	Initial    int      `yaml:"initial"`
+	Thereafter int      `yaml:"thereafter"`
+	Interval   Duration `yaml:"interval"`
+}
+
+// SinkConfig describes one remote log destination. Fields that do not apply
+// to the sink's type are ignored; zero values, and unset pointers, take the
+// logger's defaults. Pointers keep an explicit 0 apart from unset.
+type SinkConfig struct {
+	Type       string `yaml:"type" json:"type"` // "syslog" or "http"
+	Name       string `yaml:"name" json:"name,omitempty"`
+	BufferSize int    `yaml:"buffer_size" json:"buffer_size,omitempty"`
+
+	// syslog
+	Network  string `yaml:"network" json:"network,omitempty"` // "udp" or "tcp"
+	Address  string `yaml:"address" json:"address,omitempty"`
+	AppName  string `yaml:"app_name" json:"app_name,omitempty"`
+	Facility *int   `yaml:"facility" json:"facility,omitempty"`
+
+	// http
+	URL           string   `yaml:"url" json:"url,omitempty"`
+	Authorization Secret   `yaml:"authorization" json:"authorization,omitempty"`
+	BatchSize     int      `yaml:"batch_size" json:"batch_size,omitempty"`
+	FlushInterval Duration `yaml:"flush_interval" json:"flush_interval,omitempty"`
+	MaxRetries    *int     `yaml:"max_retries" json:"max_retries,omitempty"`
+	Timeout       Duration `yaml:"timeout" json:"timeout,omitempty"`
+}
+
+// AuditConfig holds the settings of the audit log of admin actions, which is
+// kept apart from the application log.
+type AuditConfig struct {
+	Dir           string `yaml:"dir"`
+	RetentionDays int    `yaml:"retention_days"`
+}
+
+// HealthConfig tunes the readiness checks behind /healthz/ready.
+type HealthConfig struct {
+	Timeout Duration `yaml:"timeout"` // per check
+	// ProviderInterval is how often the provider is actually pinged, and the
+	// log directory probed; probes in between reuse the last result so they
+	// cost no quota and create no files.
+	ProviderInterval Duration `yaml:"provider_interval"`
+	// MaxErrorRate is the highest fraction of failed upstream calls over
+	// the last five minutes at which the server is still ready.
+	MaxErrorRate float64 `yaml:"max_error_rate"`
+}
+
+// TracingConfig selects where OpenTelemetry spans go and how many traces
+// are sampled.
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"` // none, stdout, file or otlp
+	Endpoint string `yaml:"endpoint"` // OTLP/HTTP collector, host:port
+	Insecure bool   `yaml:"insecure"` // OTLP over plain HTTP
+	File     string `yaml:"file"`     // for the file exporter
+	// SampleRatio is the fraction of new traces recorded; requests that
+	// carry a sampled traceparent are always recorded.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// RateLimitConfig throttles calls to the upstream provider, and each
+// client's requests to the data endpoints.
+type RateLimitConfig struct {
+	RequestsPerMinute int                   `yaml:"requests_per_minute"`
+	Clients           ClientRateLimitConfig `yaml:"clients"`
+}
+
+// ClientRateLimitConfig limits how often one client, by IP address or admin
+// bearer token, may request the /data/ endpoints, so cache misses it causes
+// cannot use up the provider quota.
+type ClientRateLimitConfig struct {
+	// RequestsPerMinute is the sustained rate; 0 disables the limit.
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// Burst is how many requests a client may make at once, such as the
+	// widgets loading together when the dashboard opens.
+	Burst int `yaml:"burst"`
+}
+
+// DashboardConfig controls what the dashboard shows and how often it polls.
+type DashboardConfig struct {
+	TickerLimit     int             `yaml:"ticker_limit"`
+	Tickers         []string        `yaml:"tickers"`
+	PollingInterval Duration        `yaml:"polling_interval"`
+	Refresh         RefreshSettings `yaml:"refresh"`
 }
 
-// Load reads the YAML config file and returns the Config struct
+// RefreshSettings holds the HTMX polling interval of each widget.
+type RefreshSettings struct {
+	MostActive Duration `yaml:"most_active"`
+	Gainers    Duration `yaml:"gainers"`
+	Losers     Duration `yaml:"losers"`
+	Profile    Duration `yaml:"profile"`
+	News       Duration `yaml:"news"`
+}
+
+// Default returns a Config populated with the built-in defaults.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              8080,
+			ShutdownTimeout:   Seconds(5),
+			ReadHeaderTimeout: Seconds(5),
+			ReadTimeout:       Seconds(15),
+			WriteTimeout:      Seconds(30),
+			IdleTimeout:       Seconds(120),
+		},
+		Admin: AdminConfig{
+			Address: "localhost:9090",
+			Auth: AdminAuthConfig{
+				MaxFailures: 5,
+				Lockout:     Seconds(300),
+			},
+		},
+		TLS: TLSConfig{
+			ReloadInterval: Seconds(30),
+		},
+		Provider: ProviderConfig{
+			Name:      "finnhub",
+			APIKeyEnv: DefaultAPIKeyEnv,
+		},
+		Cache: CacheConfig{
+			TTL:        Seconds(60),
+			ProfileTTL: Seconds(300),
+			NewsTTL:    Seconds(120),
+		},
+		Logging: LoggingConfig{
+			Level:        "info",
+			Format:       "json",
+			Path:         "logs/app.log",
+			Compress:     true,
+			MaxSizeMB:    10,
+			MaxAgeDays:   5,
+			MaxFiles:     10,
+			DedupeWindow: Seconds(10),
+			Sampling: SamplingConfig{
+				Thereafter: 100,
+				Interval:   Seconds(1),
+			},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerMinute: 60,
+			Clients: ClientRateLimitConfig{
+				RequestsPerMinute: 60,
+				Burst:             20,
+			},
+		},
+		Dashboard: DashboardConfig{
+			TickerLimit:     10,
+			Tickers:         []string{"AAPL", "GOOGL", "MSFT", "AMZN", "TSLA"},
+			PollingInterval: Seconds(15),
+			Refresh: RefreshSettings{
+				MostActive: Seconds(15),
+				Gainers:    Seconds(20),
+				Losers:     Seconds(20),
+				Profile:    Seconds(30),
+				News:       Seconds(60),
+			},
+		},
+		Audit: AuditConfig{
+			Dir:           "logs/audit",
+			RetentionDays: 365,
+		},
+		Health: HealthConfig{
+			Timeout:          Seconds(5),
+			ProviderInterval: Seconds(30),
+			MaxErrorRate:     0.5,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			File:        "logs/traces.jsonl",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// fileConfig is the on-disk shape of the config file. It accepts the flat
+// keys of the original format alongside the sectioned schema.
+type fileConfig struct {
+	Config `yaml:",inline"`
+
+	LegacyAPIKey          string `yaml:"polygon_api_key"`
+	LegacyCacheTTL        int    `yaml:"cache_ttl_seconds"`
+	LegacyPollingInterval int    `yaml:"polling_interval_seconds"`
+	LegacyTickerLimit     int    `yaml:"ticker_limit"`
+}
+
+// Load reads the YAML config file, fills in defaults and validates the result.
+// Unknown keys are rejected.
 func Load(path string) (*Config, error) {
+	return LoadWithFlags(path, nil)
+}
+
+// LoadWithFlags is like Load, but applies flags, a map of dotted keys to
+// values given on the command line. Precedence, highest first, is flags,
+// environment variables, the file and then defaults.
+func LoadWithFlags(path string, flags map[string]string) (*Config, error) {
 	file, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
+	return parse(file, flags)
+}
 
-	var cfg Config
-	err = yaml.Unmarshal(file, &cfg)
-	if err != nil {
+// Parse decodes YAML config data, fills in defaults and validates the result.
+func Parse(data []byte) (*Config, error) {
+	return parse(data, nil)
+}
+
+func parse(data []byte, flags map[string]string) (*Config, error) {
+	fc := fileConfig{Config: Default()}
+	if err := yaml.UnmarshalStrict(data, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	var raw map[interface{}]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	keys := presentKeys(raw, "")
 
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("polygon_api_key is required in config")
+	cfg := fc.Config
+	cfg.sources = make(map[string]Source)
+	for _, f := range cfg.Fields() {
+		if keys[f.Key] {
+			cfg.sources[f.Key] = SourceFile
+		}
 	}
+	fc.applyLegacy(&cfg, keys)
 
+	if err := cfg.applyEnv(); err != nil {
+		return nil, fmt.Errorf("failed to apply environment: %w", err)
+	}
+	cfg.flags = flags
+	for key, value := range flags {
+		if err := cfg.set(key, value, SourceFlag); err != nil {
+			return nil, fmt.Errorf("failed to apply flag: %w", err)
+		}
+	}
+
+	errs := &ValidationError{}
+	if src := cfg.Provider.resolveAPIKey(errs); src != "" {
+		cfg.sources["provider.api_key"] = src
+	}
+	cfg.validate(errs)
+	if len(errs.Problems) > 0 {
+		return nil, errs
+	}
 	return &cfg, nil
 }
+
+// applyLegacy copies the flat keys of the original format into their new
+// sections, unless the file also sets the sectioned key.
+func (fc fileConfig) applyLegacy(cfg *Config, keys map[string]bool) {
+	if fc.LegacyAPIKey != "" && !keys["provider.api_key"] {
+		cfg.Provider.APIKey = Secret(fc.LegacyAPIKey)
+		cfg.sources["provider.api_key"] = SourceFile
+	}
+	if fc.LegacyCacheTTL != 0 && !keys["cache.ttl"] {
+		cfg.Cache.TTL = Seconds(fc.LegacyCacheTTL)
+		cfg.sources["cache.ttl"] = SourceFile
+	}
+	if fc.LegacyPollingInterval != 0 && !keys["dashboard.polling_interval"] {
+		cfg.Dashboard.PollingInterval = Seconds(fc.LegacyPollingInterval)
+		cfg.sources["dashboard.polling_interval"] = SourceFile
+	}
+	if fc.LegacyTickerLimit != 0 && !keys["dashboard.ticker_limit"] {
+		cfg.Dashboard.TickerLimit = fc.LegacyTickerLimit
+		cfg.sources["dashboard.ticker_limit"] = SourceFile
+	}
+}
+
+// presentKeys flattens the keys set in a decoded YAML document into dotted paths.
+func presentKeys(m map[interface{}]interface{}, prefix string) map[string]bool {
+	keys := make(map[string]bool)
+	for k, v := range m {
+		path := fmt.Sprint(k)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		keys[path] = true
+		if nested, ok := v.(map[interface{}]interface{}); ok {
+			for p := range presentKeys(nested, path) {
+				keys[p] = true
+			}
+		}
+	}
+	return keys
+}
+
+// Duration is a time.Duration that decodes from YAML as either a Go duration
+// string ("90s", "5m") or a whole number of seconds.
+type Duration struct {
+	time.Duration
+}
+
+// Seconds returns a Duration of n seconds.
+func Seconds(n int) Duration {
+	return Duration{time.Duration(n) * time.Second}
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var n int
+	if err := unmarshal(&n); err == nil {
+		*d = Seconds(n)
+		return nil
+	}
+
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
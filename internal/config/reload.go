@@ -0,0 +1,154 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Store holds the active Config and swaps in reloaded settings atomically.
+// Readers should call Current on every use rather than keeping the pointer.
+type Store struct {
+	path    string
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex // serialises reloads
+	listeners []func(old, new *Config)
+}
+
+// ReloadResult describes the outcome of a successful reload.
+type ReloadResult struct {
+	// Applied lists the runtime settings that were swapped in.
+	Applied []Change
+	// RequiresRestart lists changed settings that only take effect on restart.
+	RequiresRestart []Change
+}
+
+// NewStore creates a Store for the config file at path, seeded with cfg.
+func NewStore(path string, cfg *Config) *Store {
+	s := &Store{path: path}
+	s.current.Store(cfg)
+	return s
+}
+
+// Current returns the active config. The returned value must not be modified.
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// OnReload registers fn to run after new settings have been swapped in.
+func (s *Store) OnReload(fn func(old, new *Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Reload re-reads and validates the config file. If it is valid, the settings
+// that can change at runtime are swapped in; an invalid file leaves the active
+// config untouched.
+func (s *Store) Reload() (ReloadResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.Current()
+	loaded, err := LoadWithFlags(s.path, old.flags)
+	if err != nil {
+		return ReloadResult{}, err
+	}
+
+	next := withRuntimeSettings(old, loaded)
+
+	result := ReloadResult{
+		Applied:         Diff(old, next),
+		RequiresRestart: Diff(next, loaded),
+	}
+
+	if len(result.Applied) == 0 {
+		return result, nil
+	}
+
+	s.current.Store(next)
+	for _, fn := range s.listeners {
+		fn(old, next)
+	}
+	return result, nil
+}
+
+// withRuntimeSettings returns a copy of cur with the settings that are safe
+// to change on a running server taken from loaded: cache TTLs, rate and
+// ticker limits, log level and the dashboard's tickers and refresh rates.
+func withRuntimeSettings(cur, loaded *Config) *Config {
+	next := *cur
+	next.Cache = loaded.Cache
+	next.RateLimit = loaded.RateLimit
+	next.Logging.Level = loaded.Logging.Level
+	next.Dashboard = loaded.Dashboard
+	next.Dashboard.Tickers = append([]string(nil), loaded.Dashboard.Tickers...)
+
+	next.sources = make(map[string]Source, len(cur.sources))
+	for key, src := range cur.sources {
+		next.sources[key] = src
+	}
+	for _, f := range next.Fields() {
+		if isRuntimeSetting(f.Key) {
+			delete(next.sources, f.Key)
+			if src, ok := loaded.sources[f.Key]; ok {
+				next.sources[f.Key] = src
+			}
+		}
+	}
+	return &next
+}
+
+func isRuntimeSetting(key string) bool {
+	for _, prefix := range []string{"cache.", "rate_limit.", "dashboard."} {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return key == "logging.level"
+}
+
+// Watch reloads the config whenever the file's modification time or size
+// changes, checking every interval, and whenever the process receives SIGHUP.
+// report is called with the outcome of each reload attempt. Watch returns
+// when ctx is cancelled.
+func (s *Store) Watch(ctx context.Context, interval time.Duration, report func(ReloadResult, error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastMod, lastSize := s.stat()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			lastMod, lastSize = s.stat()
+			report(s.Reload())
+		case <-ticker.C:
+			mod, size := s.stat()
+			if mod.Equal(lastMod) && size == lastSize {
+				continue
+			}
+			lastMod, lastSize = mod, size
+			report(s.Reload())
+		}
+	}
+}
+
+func (s *Store) stat() (time.Time, int64) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
@@ -0,0 +1,63 @@
+package config
+
+import "testing"
+
+func TestWithRuntimeSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		modify  func(*Config)
+		applied bool
+	}{
+		{"cache TTL", "cache.ttl", func(c *Config) { c.Cache.TTL = Seconds(30) }, true},
+		{"rate limit", "rate_limit.clients.burst", func(c *Config) { c.RateLimit.Clients.Burst = 5 }, true},
+		{"dashboard tickers", "dashboard.tickers", func(c *Config) { c.Dashboard.Tickers = []string{"NVDA"} }, true},
+		{"dashboard refresh", "dashboard.refresh.news", func(c *Config) { c.Dashboard.Refresh.News = Seconds(90) }, true},
+		{"log level", "logging.level", func(c *Config) { c.Logging.Level = "debug" }, true},
+		{"log format", "logging.format", func(c *Config) { c.Logging.Format = "logfmt" }, false},
+		{"server port", "server.port", func(c *Config) { c.Server.Port = 9090 }, false},
+		{"provider key", "provider.api_key", func(c *Config) { c.Provider.APIKey = "other" }, false},
+		{"admin lockout", "admin.auth.lockout", func(c *Config) { c.Admin.Auth.Lockout = Seconds(60) }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cur := Default()
+			cur.sources = map[string]Source{"server.port": SourceEnv}
+			loaded := Default()
+			loaded.sources = map[string]Source{tt.key: SourceFile}
+			tt.modify(&loaded)
+
+			next := withRuntimeSettings(&cur, &loaded)
+
+			applied := Diff(&cur, next)
+			pending := Diff(next, &loaded)
+			if tt.applied {
+				if len(applied) != 1 || applied[0].Key != tt.key || len(pending) != 0 {
+					t.Errorf("applied %v, still pending %v; want only %s applied", applied, pending, tt.key)
+				}
+				if got := next.Source(tt.key); got != SourceFile {
+					t.Errorf("Source(%q) = %q, want %q", tt.key, got, SourceFile)
+				}
+			} else {
+				if len(applied) != 0 || len(pending) != 1 || pending[0].Key != tt.key {
+					t.Errorf("applied %v, still pending %v; want only %s pending", applied, pending, tt.key)
+				}
+				if got, want := next.Source(tt.key), cur.Source(tt.key); got != want {
+					t.Errorf("Source(%q) = %q, want %q", tt.key, got, want)
+				}
+			}
+			if got := next.Source("server.port"); got != SourceEnv {
+				t.Errorf("Source(server.port) = %q, want %q", got, SourceEnv)
+			}
+		})
+	}
+}
+
+func TestWithRuntimeSettingsCopiesTickers(t *testing.T) {
+	cur, loaded := Default(), Default()
+	next := withRuntimeSettings(&cur, &loaded)
+	loaded.Dashboard.Tickers[0] = "NVDA"
+	if next.Dashboard.Tickers[0] != "AAPL" {
+		t.Errorf("Tickers[0] = %q after changing the loaded config, want AAPL", next.Dashboard.Tickers[0])
+	}
+}
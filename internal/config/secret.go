@@ -0,0 +1,103 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultAPIKeyEnv is the environment variable checked for the provider API
+// key when provider.api_key_env is not set.
+const DefaultAPIKeyEnv = "FINNHUB_API_KEY"
+
+// secretCommandTimeout bounds how long provider.api_key_command may run.
+const secretCommandTimeout = 10 * time.Second
+
+// Secret is a string that never prints its value. Formatting it with any verb,
+// or marshalling it to YAML or JSON, yields "[REDACTED]"; use Value to read it.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+// Value returns the secret in plaintext.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Format implements fmt.Formatter so that no verb, including %#v and %x,
+// prints the secret.
+func (s Secret) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, s.String())
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (p *ProviderConfig) apiKeyEnv() string {
+	if p.APIKeyEnv == "" {
+		return DefaultAPIKeyEnv
+	}
+	return p.APIKeyEnv
+}
+
+// resolveAPIKey fills Provider.APIKey from the configured secret source and
+// returns that source, or "" if APIKey was left as it was. The environment
+// variable takes precedence over api_key, api_key_file and api_key_command,
+// of which at most one may be set.
+func (p *ProviderConfig) resolveAPIKey(errs *ValidationError) Source {
+	sources := 0
+	for _, set := range []bool{p.APIKey != "", p.APIKeyFile != "", p.APIKeyCommand != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		errs.addf("provider: only one of api_key, api_key_file and api_key_command may be set")
+		return ""
+	}
+
+	if v := strings.TrimSpace(os.Getenv(p.apiKeyEnv())); v != "" {
+		p.APIKey = Secret(v)
+		return SourceEnv
+	}
+
+	switch {
+	case p.APIKeyFile != "":
+		data, err := os.ReadFile(p.APIKeyFile)
+		if err != nil {
+			errs.addf("provider.api_key_file could not be read: %v", err)
+			return ""
+		}
+		p.APIKey = Secret(strings.TrimSpace(string(data)))
+		return SourceSecretFile
+	case p.APIKeyCommand != "":
+		ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "sh", "-c", p.APIKeyCommand).Output()
+		if err != nil {
+			errs.addf("provider.api_key_command failed: %v", err)
+			return ""
+		}
+		p.APIKey = Secret(strings.TrimSpace(string(out)))
+		return SourceCommand
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveAPIKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	const env = "STOCKSPOTLIGHT_TEST_API_KEY"
+
+	tests := []struct {
+		name     string
+		env      string
+		provider ProviderConfig
+		wantKey  Secret
+		wantSrc  Source
+		problems []string
+	}{
+		{
+			name:     "inline key",
+			provider: ProviderConfig{APIKey: "inline"},
+			wantKey:  "inline",
+		},
+		{
+			name:     "env beats inline key",
+			env:      " from-env ",
+			provider: ProviderConfig{APIKey: "inline"},
+			wantKey:  "from-env",
+			wantSrc:  SourceEnv,
+		},
+		{
+			name:     "env beats file",
+			env:      "from-env",
+			provider: ProviderConfig{APIKeyFile: keyFile},
+			wantKey:  "from-env",
+			wantSrc:  SourceEnv,
+		},
+		{
+			name:     "file",
+			provider: ProviderConfig{APIKeyFile: keyFile},
+			wantKey:  "from-file",
+			wantSrc:  SourceSecretFile,
+		},
+		{
+			name:     "command",
+			provider: ProviderConfig{APIKeyCommand: "echo from-command"},
+			wantKey:  "from-command",
+			wantSrc:  SourceCommand,
+		},
+		{
+			name:     "more than one source",
+			env:      "from-env",
+			provider: ProviderConfig{APIKey: "inline", APIKeyFile: keyFile},
+			wantKey:  "inline",
+			problems: []string{"provider: only one of api_key, api_key_file and api_key_command may be set"},
+		},
+		{
+			name:     "failing command",
+			provider: ProviderConfig{APIKeyCommand: "exit 1"},
+			problems: []string{"provider.api_key_command failed: exit status 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(env, tt.env)
+			p := tt.provider
+			p.APIKeyEnv = env
+			errs := &ValidationError{}
+
+			src := p.resolveAPIKey(errs)
+			if p.APIKey != tt.wantKey || src != tt.wantSrc {
+				t.Errorf("resolveAPIKey() = %q from %q, want %q from %q", p.APIKey.Value(), src, tt.wantKey.Value(), tt.wantSrc)
+			}
+			if !reflect.DeepEqual(errs.Problems, tt.problems) {
+				t.Errorf("Problems = %q, want %q", errs.Problems, tt.problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Source records where a setting's value came from.
+type Source string
+
+const (
+	SourceDefault    Source = "default"
+	SourceFile       Source = "file"
+	SourceEnv        Source = "env"
+	SourceFlag       Source = "flag"
+	SourceSecretFile Source = "secret file"
+	SourceCommand    Source = "command"
+)
+
+// EnvPrefix is prepended to a setting's upper-cased, underscore-separated key
+// to form the environment variable that overrides it, e.g.
+// STOCKSPOTLIGHT_CACHE_TTL for cache.ttl.
+const EnvPrefix = "STOCKSPOTLIGHT_"
+
+// envAliases are environment variables kept for compatibility with earlier
+// releases, mapped to the setting they override.
+var envAliases = map[string]string{
+	"PORT": "server.port",
+}
+
+// EnvVar returns the environment variable that overrides key.
+func EnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_").Replace(key))
+}
+
+// Source returns where the value of key came from.
+func (c *Config) Source(key string) Source {
+	if src, ok := c.sources[key]; ok {
+		return src
+	}
+	return SourceDefault
+}
+
+// applyEnv overrides settings from environment variables.
+func (c *Config) applyEnv() error {
+	for alias, key := range envAliases {
+		if v, ok := os.LookupEnv(alias); ok && v != "" {
+			if err := c.set(key, v, SourceEnv); err != nil {
+				return fmt.Errorf("%s: %w", alias, err)
+			}
+		}
+	}
+	for _, f := range c.Fields() {
+		name := EnvVar(f.Key)
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			if err := c.set(f.Key, v, SourceEnv); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// set parses value into the setting addressed by key and records its source.
+// Lists are given as comma-separated values.
+func (c *Config) set(key, value string, src Source) error {
+	field, ok := fieldByKey(reflect.ValueOf(c).Elem(), key)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		if err := yaml.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid value %q for %s", value, key)
+		}
+	}
+
+	if c.sources == nil {
+		c.sources = make(map[string]Source)
+	}
+	c.sources[key] = src
+	return nil
+}
+
+func fieldByKey(v reflect.Value, key string) (reflect.Value, bool) {
+	name, rest, nested := strings.Cut(key, ".")
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0] != name {
+			continue
+		}
+		fv := v.Field(i)
+		if !nested {
+			return fv, fv.Kind() != reflect.Struct || fv.Type() == durationType
+		}
+		if fv.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		return fieldByKey(fv, rest)
+	}
+	return reflect.Value{}, false
+}
+
+// View is the effective configuration with secrets redacted and the source
+// of every value, as shown by `stockspotlight config print` and /admin/config.
+type View struct {
+	Config  map[string]interface{} `json:"config" yaml:"config"`
+	Sources map[string]Source      `json:"sources" yaml:"sources"`
+}
+
+// View returns the redacted effective configuration.
+func (c *Config) View() View {
+	view := View{
+		Config:  make(map[string]interface{}),
+		Sources: make(map[string]Source),
+	}
+	for _, f := range c.Fields() {
+		parts := strings.Split(f.Key, ".")
+		section := view.Config
+		for _, p := range parts[:len(parts)-1] {
+			next, ok := section[p].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				section[p] = next
+			}
+			section = next
+		}
+		section[parts[len(parts)-1]] = f.Value
+		view.Sources[f.Key] = c.Source(f.Key)
+	}
+	return view
+}
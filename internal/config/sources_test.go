@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSettingPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     string
+		flag    string
+		want    time.Duration
+		wantSrc Source
+	}{
+		{name: "default", want: 60 * time.Second, wantSrc: SourceDefault},
+		{name: "file", file: "cache:\n  ttl: 30s\n", want: 30 * time.Second, wantSrc: SourceFile},
+		{name: "legacy file key", file: "cache_ttl_seconds: 45\n", want: 45 * time.Second, wantSrc: SourceFile},
+		{name: "env beats file", file: "cache:\n  ttl: 30s\n", env: "20s", want: 20 * time.Second, wantSrc: SourceEnv},
+		{name: "flag beats env", file: "cache:\n  ttl: 30s\n", env: "20s", flag: "10s", want: 10 * time.Second, wantSrc: SourceFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STOCKSPOTLIGHT_CACHE_TTL", tt.env)
+			var flags map[string]string
+			if tt.flag != "" {
+				flags = map[string]string{"cache.ttl": tt.flag}
+			}
+
+			cfg, err := parse([]byte("provider:\n  api_key: key\n"+tt.file), flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Cache.TTL.Duration != tt.want || cfg.Source("cache.ttl") != tt.wantSrc {
+				t.Errorf("cache.ttl = %v from %q, want %v from %q", cfg.Cache.TTL.Duration, cfg.Source("cache.ttl"), tt.want, tt.wantSrc)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key, value string
+		// want is the field's value after set, formatted by fmt.Sprint
+		want    string
+		wantErr bool
+	}{
+		{key: "server.port", value: "9090", want: "9090"},
+		{key: "dashboard.tickers", value: "NVDA, AMD,", want: "[NVDA AMD]"},
+		{key: "logging.sampling.interval", value: "2s", want: "2s"},
+		{key: "server.port", value: "http", wantErr: true},
+		{key: "server.nope", value: "1", wantErr: true},
+		{key: "logging.sampling", value: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := Default()
+			err := cfg.set(tt.key, tt.value, SourceFlag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("set() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Source(tt.key) != SourceFlag {
+				t.Errorf("Source(%q) = %q, want %q", tt.key, cfg.Source(tt.key), SourceFlag)
+			}
+			field, _ := fieldByKey(reflect.ValueOf(cfg), tt.key)
+			if got := fmt.Sprint(field.Interface()); got != tt.want {
+				t.Errorf("%s = %s, want %s", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Provider.APIKey = "super-secret-key"
+	cfg.Admin.Auth.Tokens = []AdminToken{{Name: "ops", Token: "super-secret-token"}}
+
+	data, err := json.Marshal(cfg.View())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "super-secret") {
+		t.Errorf("View() leaks a secret: %s", data)
+	}
+	if !strings.Contains(string(data), redacted) {
+		t.Errorf("View() = %s, want secrets shown as %s", data, redacted)
+	}
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ValidationError lists every problem found in a Config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *ValidationError) addf(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// Validate checks the config for missing or out-of-range values. All problems
+// are collected into a single *ValidationError.
+func (c *Config) Validate() error {
+	errs := &ValidationError{}
+	c.validate(errs)
+	if len(errs.Problems) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c *Config) validate(errs *ValidationError) {
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs.addf("server.port must be between 1 and 65535")
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		errs.addf("server.shutdown_timeout must be > 0")
+	}
+	if c.Server.ReadHeaderTimeout.Duration <= 0 {
+		errs.addf("server.read_header_timeout must be > 0")
+	}
+	for _, t := range []struct {
+		key string
+		d   Duration
+	}{
+		{"read_timeout", c.Server.ReadTimeout},
+		{"write_timeout", c.Server.WriteTimeout},
+		{"idle_timeout", c.Server.IdleTimeout},
+	} {
+		if t.d.Duration < 0 {
+			errs.addf("server.%s must be >= 0", t.key)
+		}
+	}
+	if _, err := c.Server.ParseTrustedProxies(); err != nil {
+		errs.addf("server.trusted_proxies: %v", err)
+	}
+	if c.Admin.Address == "" {
+		if !c.Admin.Auth.Enabled() {
+			errs.addf("admin.auth needs tokens or users when admin.address is empty and the admin endpoints share the public port")
+		}
+	} else {
+		if network, address := c.Admin.Listen(); network == "unix" {
+			if address == "" {
+				errs.addf("admin.address unix: needs a socket path")
+			}
+		} else if _, _, err := net.SplitHostPort(address); err != nil {
+			errs.addf("admin.address must be host:port or unix:/path: %v", err)
+		}
+	}
+	c.validateAdminAuth(errs)
+	c.validateTLS(errs)
+
+	if c.Provider.Name != "finnhub" {
+		errs.addf("provider.name must be one of: finnhub")
+	}
+	if c.Provider.APIKey == "" {
+		errs.addf("provider.api_key is required (or set %s, provider.api_key_file or provider.api_key_command)", c.Provider.apiKeyEnv())
+	}
+
+	if c.Cache.TTL.Duration <= 0 {
+		errs.addf("cache.ttl must be > 0")
+	}
+	if c.Cache.ProfileTTL.Duration <= 0 {
+		errs.addf("cache.profile_ttl must be > 0")
+	}
+	if c.Cache.NewsTTL.Duration <= 0 {
+		errs.addf("cache.news_ttl must be > 0")
+	}
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs.addf("logging.level must be one of: debug, info, warn, error")
+	}
+	switch c.Logging.Format {
+	case "json", "logfmt":
+	default:
+		errs.addf("logging.format must be one of: json, logfmt")
+	}
+	if c.Logging.Path == "" {
+		errs.addf("logging.path is required")
+	}
+	if c.Logging.MaxSizeMB <= 0 {
+		errs.addf("logging.max_size_mb must be > 0")
+	}
+	if c.Logging.MaxAgeDays <= 0 {
+		errs.addf("logging.max_age_days must be > 0")
+	}
+	if c.Logging.MaxFiles <= 0 {
+		errs.addf("logging.max_files must be > 0")
+	}
+	if c.Logging.MaxTotalSizeMB < 0 || (c.Logging.MaxTotalSizeMB > 0 && c.Logging.MaxTotalSizeMB < c.Logging.MaxSizeMB) {
+		errs.addf("logging.max_total_size_mb must be 0 (no cap) or at least logging.max_size_mb")
+	}
+	if c.Logging.DedupeWindow.Duration < 0 {
+		errs.addf("logging.dedupe_window must not be negative")
+	}
+	if c.Logging.Sampling.Initial < 0 {
+		errs.addf("logging.sampling.initial must be >= 0")
+	}
+	if c.Logging.Sampling.Initial > 0 {
+		if c.Logging.Sampling.Thereafter <= 0 {
+			errs.addf("logging.sampling.thereafter must be > 0")
+		}
+		if c.Logging.Sampling.Interval.Duration <= 0 {
+			errs.addf("logging.sampling.interval must be > 0")
+		}
+	}
+	for i, sink := range c.Logging.Sinks {
+		validateSink(errs, fmt.Sprintf("logging.sinks[%d]", i), sink)
+	}
+
+	if c.RateLimit.RequestsPerMinute <= 0 {
+		errs.addf("rate_limit.requests_per_minute must be > 0")
+	}
+	if c.RateLimit.Clients.RequestsPerMinute < 0 {
+		errs.addf("rate_limit.clients.requests_per_minute must be >= 0")
+	}
+	if c.RateLimit.Clients.RequestsPerMinute > 0 && c.RateLimit.Clients.Burst <= 0 {
+		errs.addf("rate_limit.clients.burst must be > 0")
+	}
+
+	if c.Dashboard.TickerLimit <= 0 {
+		errs.addf("dashboard.ticker_limit must be > 0")
+	}
+	if len(c.Dashboard.Tickers) == 0 {
+		errs.addf("dashboard.tickers must not be empty")
+	}
+	for i, t := range c.Dashboard.Tickers {
+		if strings.TrimSpace(t) == "" {
+			errs.addf("dashboard.tickers[%d] must not be blank", i)
+		}
+	}
+	if c.Dashboard.PollingInterval.Duration <= 0 {
+		errs.addf("dashboard.polling_interval must be > 0")
+	}
+	refresh := []struct {
+		name string
+		d    Duration
+	}{
+		{"most_active", c.Dashboard.Refresh.MostActive},
+		{"gainers", c.Dashboard.Refresh.Gainers},
+		{"losers", c.Dashboard.Refresh.Losers},
+		{"profile", c.Dashboard.Refresh.Profile},
+		{"news", c.Dashboard.Refresh.News},
+	}
+	for _, r := range refresh {
+		if r.d.Duration < time.Second {
+			errs.addf("dashboard.refresh.%s must be at least 1s", r.name)
+		}
+	}
+
+	if c.Audit.Dir == "" {
+		errs.addf("audit.dir is required")
+	}
+	if c.Audit.RetentionDays <= 0 {
+		errs.addf("audit.retention_days must be > 0")
+	}
+
+	if c.Health.Timeout.Duration <= 0 {
+		errs.addf("health.timeout must be > 0")
+	}
+	if c.Health.ProviderInterval.Duration <= 0 {
+		errs.addf("health.provider_interval must be > 0")
+	}
+	if c.Health.MaxErrorRate <= 0 || c.Health.MaxErrorRate > 1 {
+		errs.addf("health.max_error_rate must be in (0, 1]")
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "file":
+		if c.Tracing.File == "" {
+			errs.addf("tracing.file is required for the file exporter")
+		}
+	case "otlp":
+		if _, _, err := net.SplitHostPort(c.Tracing.Endpoint); err != nil {
+			errs.addf("tracing.endpoint must be host:port")
+		}
+	default:
+		errs.addf("tracing.exporter must be one of: none, stdout, file, otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs.addf("tracing.sample_ratio must be between 0 and 1")
+	}
+}
+
+func validateSink(errs *ValidationError, key string, sink SinkConfig) {
+	switch sink.Type {
+	case "syslog":
+		switch sink.Network {
+		case "udp", "tcp":
+		default:
+			errs.addf("%s.network must be one of: udp, tcp", key)
+		}
+		if _, _, err := net.SplitHostPort(sink.Address); err != nil {
+			errs.addf("%s.address must be host:port", key)
+		}
+		if sink.Facility != nil && (*sink.Facility < 0 || *sink.Facility > 23) {
+			errs.addf("%s.facility must be between 0 and 23", key)
+		}
+	case "http":
+		if u, err := url.Parse(sink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.addf("%s.url must be an http or https URL", key)
+		}
+		if sink.BatchSize < 0 {
+			errs.addf("%s.batch_size must be >= 0", key)
+		}
+		if sink.MaxRetries != nil && *sink.MaxRetries < 0 {
+			errs.addf("%s.max_retries must be >= 0", key)
+		}
+		if sink.FlushInterval.Duration < 0 || sink.Timeout.Duration < 0 {
+			errs.addf("%s.flush_interval and timeout must not be negative", key)
+		}
+	default:
+		errs.addf("%s.type must be one of: syslog, http", key)
+	}
+	if sink.BufferSize < 0 {
+		errs.addf("%s.buffer_size must be >= 0", key)
+	}
+}
+
+// minAdminTokenLength keeps static admin tokens out of brute-force range.
+const minAdminTokenLength = 16
+
+func (c *Config) validateAdminAuth(errs *ValidationError) {
+	auth := c.Admin.Auth
+	names := make(map[string]bool)
+	for i, t := range auth.Tokens {
+		if t.Name == "" {
+			errs.addf("admin.auth.tokens[%d].name is required", i)
+		} else if names[t.Name] {
+			errs.addf("admin.auth.tokens[%d].name %q is used twice", i, t.Name)
+		}
+		names[t.Name] = true
+		if len(t.Token) < minAdminTokenLength {
+			errs.addf("admin.auth.tokens[%d].token must be at least %d characters", i, minAdminTokenLength)
+		}
+	}
+	users := make(map[string]bool)
+	for i, u := range auth.Users {
+		if u.Username == "" || strings.Contains(u.Username, ":") {
+			errs.addf("admin.auth.users[%d].username is required and must not contain ':'", i)
+		} else if users[u.Username] {
+			errs.addf("admin.auth.users[%d].username %q is used twice", i, u.Username)
+		}
+		users[u.Username] = true
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			errs.addf("admin.auth.users[%d].password_hash is not a bcrypt hash", i)
+		}
+	}
+	if auth.MaxFailures <= 0 {
+		errs.addf("admin.auth.max_failures must be > 0")
+	}
+	if auth.Lockout.Duration <= 0 {
+		errs.addf("admin.auth.lockout must be > 0")
+	}
+}
+
+func (c *Config) validateTLS(errs *ValidationError) {
+	t := c.TLS
+	if t.Enabled() && (t.CertFile == "" || t.KeyFile == "") {
+		errs.addf("tls.cert_file and tls.key_file must be set together")
+	}
+	if t.ReloadInterval.Duration <= 0 {
+		errs.addf("tls.reload_interval must be > 0")
+	}
+	if t.RedirectAddress != "" {
+		if !t.Enabled() {
+			errs.addf("tls.redirect_address needs tls.cert_file and tls.key_file")
+		}
+		if _, _, err := net.SplitHostPort(t.RedirectAddress); err != nil {
+			errs.addf("tls.redirect_address must be host:port: %v", err)
+		}
+	}
+	if c.Admin.ClientCAFile != "" {
+		if !t.Enabled() {
+			errs.addf("admin.client_ca_file needs tls.cert_file and tls.key_file")
+		}
+		if network, _ := c.Admin.Listen(); network != "tcp" || c.Admin.Address == "" {
+			errs.addf("admin.client_ca_file needs admin.address to be host:port")
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateCollectsEveryProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{
+			name:   "defaults with an API key",
+			modify: func(c *Config) {},
+		},
+		{
+			name:   "one problem",
+			modify: func(c *Config) { c.Server.Port = 0 },
+			want:   []string{"server.port must be between 1 and 65535"},
+		},
+		{
+			name: "problems across sections",
+			modify: func(c *Config) {
+				c.Server.Port = 70000
+				c.Provider.APIKey = ""
+				c.Cache.TTL = Seconds(0)
+				c.Logging.Level = "verbose"
+				c.Dashboard.Tickers = []string{"AAPL", " "}
+			},
+			want: []string{
+				"server.port must be between 1 and 65535",
+				"provider.api_key is required (or set FINNHUB_API_KEY, provider.api_key_file or provider.api_key_command)",
+				"cache.ttl must be > 0",
+				"logging.level must be one of: debug, info, warn, error",
+				"dashboard.tickers[1] must not be blank",
+			},
+		},
+		{
+			name:   "open admin endpoints on the public port",
+			modify: func(c *Config) { c.Admin.Address = "" },
+			want:   []string{"admin.auth needs tokens or users when admin.address is empty and the admin endpoints share the public port"},
+		},
+		{
+			name: "admin endpoints on the public port with a token",
+			modify: func(c *Config) {
+				c.Admin.Address = ""
+				c.Admin.Auth.Tokens = []AdminToken{{Name: "ops", Token: "abcdefghijklmnopqrstu"}}
+			},
+		},
+		{
+			name: "sink problems are keyed by index",
+			modify: func(c *Config) {
+				retries := -1
+				c.Logging.Sinks = []SinkConfig{
+					{Type: "syslog", Network: "udp", Address: "localhost:514"},
+					{Type: "http", URL: "ftp://example.com", MaxRetries: &retries},
+				}
+			},
+			want: []string{
+				"logging.sinks[1].url must be an http or https URL",
+				"logging.sinks[1].max_retries must be >= 0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Provider.APIKey = "key"
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			if !reflect.DeepEqual(verr.Problems, tt.want) {
+				t.Errorf("Problems = %q, want %q", verr.Problems, tt.want)
+			}
+		})
+	}
+}
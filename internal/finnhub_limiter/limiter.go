@@ -1,10 +1,16 @@
 package finnhub_limiter
 
 import (
+	"context"
 	"sync"
 	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var tracer = tracing.Tracer("internal/finnhub_limiter")
+
 // Limiter controls the rate of API requests.
 type Limiter struct {
 	lastRequest time.Time
@@ -19,14 +25,47 @@ func NewLimiter(interval time.Duration) *Limiter {
 	}
 }
 
-// Wait waits until it's safe to make another request.
-func (l *Limiter) Wait() {
+// Wait waits until it's safe to make another request, or until ctx is done,
+// in which case it returns ctx.Err() and gives up its turn. Each caller
+// reserves the next free slot, so waiters do not block one another.
+func (l *Limiter) Wait(ctx context.Context) (err error) {
+	_, span := tracer.Start(ctx, "Limiter.Wait")
+	start := time.Now()
+	defer func() {
+		waited := time.Since(start)
+		waitSeconds.Observe(waited.Seconds())
+		span.SetAttributes(attribute.Int64("limiter.wait_ms", waited.Milliseconds()))
+		tracing.End(span, err)
+	}()
+
 	l.mu.Lock()
-	defer l.mu.Unlock()
+	prev := l.lastRequest
+	slot := time.Now()
+	if next := prev.Add(l.interval); next.After(slot) {
+		slot = next
+	}
+	l.lastRequest = slot
+	l.mu.Unlock()
 
-	elapsed := time.Since(l.lastRequest)
-	if elapsed < l.interval {
-		time.Sleep(l.interval - elapsed)
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		// Hand the slot back unless a later caller has reserved after it
+		if l.lastRequest.Equal(slot) {
+			l.lastRequest = prev
+		}
+		l.mu.Unlock()
+		return ctx.Err()
 	}
-	l.lastRequest = time.Now()
+}
+
+// SetInterval changes the interval between requests.
+func (l *Limiter) SetInterval(interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval = interval
 }
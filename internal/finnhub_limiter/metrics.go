@@ -0,0 +1,14 @@
+package finnhub_limiter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var waitSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace: "stockspotlight",
+	Subsystem: "limiter",
+	Name:      "wait_seconds",
+	Help:      "Time callers spent waiting in Limiter.Wait, including waits cut short by cancellation.",
+	Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+})
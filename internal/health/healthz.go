@@ -2,18 +2,122 @@
 package health
 
 import (
-	"fmt"
+	"encoding/json"
 	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/buildinfo"
 )
 
-// Handler responds with a basic 200 OK health check
-func Handler(w http.ResponseWriter, r *http.Request) {
+// Fetches records when each dashboard widget last got fresh data from the
+// provider.
+type Fetches struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// NewFetches returns an empty Fetches.
+func NewFetches() *Fetches {
+	return &Fetches{last: make(map[string]time.Time)}
+}
+
+// Record notes a successful upstream fetch for widget.
+func (f *Fetches) Record(widget string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.last[widget] = time.Now()
+}
+
+// Snapshot returns the last successful fetch time per widget.
+func (f *Fetches) Snapshot() map[string]time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	last := make(map[string]time.Time, len(f.last))
+	for widget, t := range f.last {
+		last[widget] = t
+	}
+	return last
+}
+
+// Memory is a summary of the Go runtime's memory use, in bytes.
+type Memory struct {
+	HeapAlloc uint64 `json:"heapAlloc"`
+	HeapInuse uint64 `json:"heapInuse"`
+	Sys       uint64 `json:"sys"`
+	NumGC     uint32 `json:"numGC"`
+}
+
+// Status is the /healthz response: what is running and how it is doing.
+type Status struct {
+	Status        string               `json:"status"`
+	Build         buildinfo.Info       `json:"build"`
+	StartedAt     time.Time            `json:"startedAt"`
+	Uptime        string               `json:"uptime"`
+	UptimeSeconds int64                `json:"uptimeSeconds"`
+	Goroutines    int                  `json:"goroutines"`
+	Memory        Memory               `json:"memory"`
+	Provider      string               `json:"provider"`
+	CacheEntries  int                  `json:"cacheEntries"`
+	LastFetch     map[string]time.Time `json:"lastFetch"`
+}
+
+// Reporter assembles the Status from the parts of the app it describes.
+type Reporter struct {
+	Started   time.Time
+	Provider  func() string
+	CacheSize func() int
+	Fetches   *Fetches
+}
+
+// Status takes a snapshot of the running process.
+func (rep *Reporter) Status() Status {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	uptime := time.Since(rep.Started)
+
+	return Status{
+		Status:        "ok",
+		Build:         buildinfo.Get(),
+		StartedAt:     rep.Started,
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		Memory: Memory{
+			HeapAlloc: mem.HeapAlloc,
+			HeapInuse: mem.HeapInuse,
+			Sys:       mem.Sys,
+			NumGC:     mem.NumGC,
+		},
+		Provider:     rep.Provider(),
+		CacheEntries: rep.CacheSize(),
+		LastFetch:    rep.Fetches.Snapshot(),
+	}
+}
+
+// Handler responds with the JSON Status.
+func (rep *Reporter) Handler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	writeJSON(w, rep.Status())
+}
+
+// VersionHandler responds with the JSON build metadata.
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, buildinfo.Get())
+}
 
-	w.Header().Set("Content-Type", "text/plain")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintln(w, "OK")
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
 }
@@ -0,0 +1,50 @@
+// File: internal/logger/attrs.go
+package logger
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Attribute keys shared across the app, so log queries can rely on them.
+const (
+	KeySymbol     = "symbol"
+	KeySignal     = "signal"
+	KeyCacheHit   = "cache_hit"
+	KeyDurationMS = "duration_ms"
+	KeyProvider   = "provider"
+	KeyError      = "error"
+	KeyTraceID    = "trace_id"
+	KeySpanID     = "span_id"
+	KeyActor      = "actor"
+)
+
+// Symbol returns the attribute for a ticker symbol.
+func Symbol(symbol string) slog.Attr {
+	return slog.String(KeySymbol, symbol)
+}
+
+// Signal returns the attribute for a screener signal such as "gainers".
+func Signal(signal string) slog.Attr {
+	return slog.String(KeySignal, signal)
+}
+
+// CacheHit returns the attribute recording whether data came from the cache.
+func CacheHit(hit bool) slog.Attr {
+	return slog.Bool(KeyCacheHit, hit)
+}
+
+// Duration returns the attribute for an elapsed time in milliseconds.
+func Duration(d time.Duration) slog.Attr {
+	return slog.Int64(KeyDurationMS, d.Milliseconds())
+}
+
+// Provider returns the attribute for the upstream data provider's name.
+func Provider(name string) slog.Attr {
+	return slog.String(KeyProvider, name)
+}
+
+// Err returns the attribute for an error.
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
@@ -0,0 +1,183 @@
+// File: internal/logger/http_sink.go
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPSinkConfig configures a sink that POSTs batches of records as a JSON array.
+type HTTPSinkConfig struct {
+	Name          string
+	URL           string
+	Authorization string // optional Authorization header value
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxRetries    *int // 3 if nil; 0 sends each batch once
+	Timeout       time.Duration
+	BufferSize    int
+}
+
+// httpSink batches records and POSTs them, retrying failed batches with
+// exponential backoff.
+type httpSink struct {
+	*sinkQueue
+	cfg    HTTPSinkConfig
+	client *http.Client
+}
+
+// NewHTTPSink starts a sink that ships batches of records to an HTTP endpoint.
+func NewHTTPSink(cfg HTTPSinkConfig) Sink {
+	if cfg.Name == "" {
+		cfg.Name = "http"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 2 * time.Second
+	}
+	if cfg.MaxRetries == nil {
+		retries := 3
+		cfg.MaxRetries = &retries
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1000
+	}
+
+	s := &httpSink{
+		sinkQueue: newSinkQueue(cfg.Name, "http", cfg.BufferSize),
+		cfg:       cfg,
+		client:    &http.Client{Timeout: cfg.Timeout},
+	}
+	go s.run()
+	return s
+}
+
+func (s *httpSink) Close(timeout time.Duration) error {
+	s.shutdown(timeout)
+	return nil
+}
+
+func (s *httpSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.cfg.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			s.deliver(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case p := <-s.records:
+			batch = append(batch, p)
+			if len(batch) >= s.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.closing:
+			for {
+				select {
+				case p := <-s.records:
+					batch = append(batch, p)
+					if len(batch) >= s.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver POSTs one batch, retrying with exponential backoff on network
+// errors, 429 and 5xx responses.
+func (s *httpSink) deliver(batch [][]byte) {
+	body, err := encodeBatch(batch)
+	if err != nil {
+		s.setError(err)
+		s.failed.Add(int64(len(batch)))
+		return
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retryable, err := s.post(body)
+		if err == nil {
+			s.delivered.Add(int64(len(batch)))
+			return
+		}
+		s.setError(err)
+		if !retryable || attempt >= *s.cfg.MaxRetries {
+			s.failed.Add(int64(len(batch)))
+			return
+		}
+
+		s.retries.Add(1)
+		select {
+		case <-time.After(backoff):
+		case <-s.closing:
+			// Shutting down: one last attempt without waiting
+		}
+		backoff *= 2
+	}
+}
+
+func (s *httpSink) post(body []byte) (retryable bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.Authorization != "" {
+		req.Header.Set("Authorization", s.cfg.Authorization)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("log sink %s: %s", s.cfg.Name, resp.Status)
+	default:
+		return false, fmt.Errorf("log sink %s: %s", s.cfg.Name, resp.Status)
+	}
+}
+
+// encodeBatch builds a JSON array of the records. JSON records are embedded
+// as objects; logfmt records become strings.
+func encodeBatch(batch [][]byte) ([]byte, error) {
+	items := make([]json.RawMessage, 0, len(batch))
+	for _, p := range batch {
+		p = bytes.TrimRight(p, "\n")
+		if json.Valid(p) {
+			items = append(items, json.RawMessage(p))
+			continue
+		}
+		quoted, err := json.Marshal(string(p))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, quoted)
+	}
+	return json.Marshal(items)
+}
@@ -2,53 +2,113 @@
 package logger
 
 import (
-	"io"
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
 	"time"
 )
 
-// Logger provides structured logging with rotation.
+var errLoggerClosed = errors.New("logger is closed")
+
+// Logger provides leveled, structured logging with rotation. Records are
+// written as JSON or logfmt through log/slog; messages below the minimum
+// level (Info by default) are discarded.
 type Logger struct {
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
-	file        *os.File
-	logPath     string
-	rotator     *LogRotator
+	slog     *slog.Logger
+	level    *slog.LevelVar
+	out      *logFile
+	rotator  *LogRotator
+	suppress *suppressor
+
+	stopScheduler chan struct{}
+	schedulerDone chan struct{}
+	closeOnce     sync.Once
+}
+
+// rotationCheckInterval is how often the scheduler checks whether the log
+// file has reached its maximum age or size.
+const rotationCheckInterval = 10 * time.Minute
+
+// Options configures a Logger.
+type Options struct {
+	// Format is "json" (the default) or "logfmt".
+	Format string
+	// Level is the initial minimum level; the zero value is LevelInfo.
+	Level Level
+	// Compress gzips rotated files in the background.
+	Compress bool
+	// Limits bounds file size, age, count and total disk use; zero fields
+	// take the defaults.
+	Limits RotationLimits
+	// Sinks receive every record as well as the log file. They are closed
+	// by Logger.Close.
+	Sinks []Sink
+	// Secrets are masked wherever they appear in a record, in addition to
+	// credential query parameters and headers.
+	Secrets []string
+	// DedupeWindow collapses repeats of a message within the window into a
+	// "previous message repeated N times" summary. Zero disables it.
+	DedupeWindow time.Duration
+	// Sampling thins out hot call sites below Warn.
+	Sampling Sampling
 }
 
 // New creates a new Logger instance with log rotation.
-func New(logPath string) (*Logger, error) {
+func New(logPath string, opts Options) (*Logger, error) {
 	// Ensure log directory exists
 	logDir := filepath.Dir(logPath)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, err
 	}
 
-	// Open log file
-	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	// Open log file (written together with stdout)
+	out, err := openLogFile(logPath)
 	if err != nil {
 		return nil, err
 	}
-
-	// Create multi-writer for both file and console (optional)
-	// Comment out the next line if you only want file logging
-	multiWriter := io.MultiWriter(file, os.Stdout)
+	out.sinks = opts.Sinks
+	for _, secret := range opts.Secrets {
+		out.redactor.AddSecret(secret)
+	}
 
 	// Create logger instance
 	logger := &Logger{
-		infoLogger:  log.New(multiWriter, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		errorLogger: log.New(multiWriter, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
-		file:        file,
-		logPath:     logPath,
-		rotator:     NewLogRotator(logPath),
+		level:         new(slog.LevelVar),
+		out:           out,
+		rotator:       NewLogRotator(logPath, opts.Limits),
+		stopScheduler: make(chan struct{}),
+		schedulerDone: make(chan struct{}),
 	}
+	logger.level.Set(opts.Level.slogLevel())
+	logger.rotator.compress = opts.Compress
 
-	// Start rotation scheduler (check every 10 minutes)
-	logger.rotator.StartRotationScheduler(10*time.Minute, func() {
-		logger.reopenLogFile()
-	})
+	handlerOpts := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       logger.level,
+		ReplaceAttr: shortSource,
+	}
+	var handler slog.Handler
+	switch opts.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(logger.out, handlerOpts)
+	case "logfmt":
+		handler = slog.NewTextHandler(logger.out, handlerOpts)
+	default:
+		out.Close()
+		return nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+	root := &rotatingHandler{Handler: handler, logger: logger}
+	logger.suppress = newSuppressor(opts.DedupeWindow, opts.Sampling, root)
+	logger.slog = slog.New(&suppressingHandler{Handler: root, s: logger.suppress})
+
+	// Start rotation scheduler; Close stops it
+	go logger.runRotationScheduler(rotationCheckInterval)
 
 	// Log initial startup message
 	logger.Info("Logger initialized with rotation enabled")
@@ -57,84 +117,195 @@ func New(logPath string) (*Logger, error) {
 	return logger, nil
 }
 
-// Info logs an informational message.
-func (l *Logger) Info(msg string) {
-	l.checkAndRotate()
-	l.infoLogger.Println(msg)
+// Slog returns the underlying *slog.Logger for logging structured fields
+// directly. Records written through it are rotated like any other.
+func (l *Logger) Slog() *slog.Logger {
+	return l.slog
+}
+
+// SetLevel changes the minimum level that is logged. It is safe to call
+// while other goroutines are logging.
+func (l *Logger) SetLevel(level Level) {
+	l.level.Set(level.slogLevel())
+}
+
+// Level returns the minimum level that is logged.
+func (l *Logger) Level() Level {
+	return levelFromSlog(l.level.Level())
 }
 
-// Error logs an error message.
-func (l *Logger) Error(msg string) {
-	l.checkAndRotate()
-	l.errorLogger.Println(msg)
+// Enabled reports whether messages at level are logged.
+func (l *Logger) Enabled(level Level) bool {
+	return l.slog.Enabled(context.Background(), level.slogLevel())
+}
+
+// Debug logs a debug message with optional key/value pairs or slog.Attrs.
+func (l *Logger) Debug(msg string, args ...any) {
+	l.log(LevelDebug, msg, args...)
+}
+
+// Info logs an informational message with optional key/value pairs or slog.Attrs.
+func (l *Logger) Info(msg string, args ...any) {
+	l.log(LevelInfo, msg, args...)
+}
+
+// Warn logs a warning message with optional key/value pairs or slog.Attrs.
+func (l *Logger) Warn(msg string, args ...any) {
+	l.log(LevelWarn, msg, args...)
+}
+
+// Error logs an error message with optional key/value pairs or slog.Attrs.
+func (l *Logger) Error(msg string, args ...any) {
+	l.log(LevelError, msg, args...)
+}
+
+// Debugf logs a formatted debug message.
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	if l.Enabled(LevelDebug) {
+		l.log(LevelDebug, fmt.Sprintf(format, v...))
+	}
 }
 
 // Infof logs a formatted informational message.
 func (l *Logger) Infof(format string, v ...interface{}) {
-	l.checkAndRotate()
-	l.infoLogger.Printf(format, v...)
+	if l.Enabled(LevelInfo) {
+		l.log(LevelInfo, fmt.Sprintf(format, v...))
+	}
+}
+
+// Warnf logs a formatted warning message.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	if l.Enabled(LevelWarn) {
+		l.log(LevelWarn, fmt.Sprintf(format, v...))
+	}
 }
 
 // Errorf logs a formatted error message.
 func (l *Logger) Errorf(format string, v ...interface{}) {
-	l.checkAndRotate()
-	l.errorLogger.Printf(format, v...)
+	if l.Enabled(LevelError) {
+		l.log(LevelError, fmt.Sprintf(format, v...))
+	}
 }
 
 // Fatal logs a fatal error and exits.
 func (l *Logger) Fatal(v ...interface{}) {
-	l.errorLogger.Fatal(v...)
+	l.log(LevelError, fmt.Sprint(v...), "fatal", true)
+	os.Exit(1)
 }
 
 // Fatalf logs a formatted fatal error and exits.
 func (l *Logger) Fatalf(format string, v ...interface{}) {
-	l.errorLogger.Fatalf(format, v...)
+	l.log(LevelError, fmt.Sprintf(format, v...), "fatal", true)
+	os.Exit(1)
 }
 
-// checkAndRotate checks if rotation is needed before each log write
-func (l *Logger) checkAndRotate() {
-	if shouldRotate, err := l.rotator.ShouldRotate(); err == nil && shouldRotate {
-		l.rotateNow()
+// log writes a record whose source is the caller of the exported method.
+func (l *Logger) log(level Level, msg string, args ...any) {
+	ctx := context.Background()
+	if !l.slog.Enabled(ctx, level.slogLevel()) {
+		return
 	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip Callers, log and the exported method
+	r := slog.NewRecord(time.Now(), level.slogLevel(), msg, pcs[0])
+	r.Add(args...)
+	_ = l.slog.Handler().Handle(ctx, r)
+}
+
+// rotatingHandler checks for rotation before each record is written.
+type rotatingHandler struct {
+	slog.Handler
+	logger *Logger
+}
+
+func (h *rotatingHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.logger.checkAndRotate()
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *rotatingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &rotatingHandler{Handler: h.Handler.WithAttrs(attrs), logger: h.logger}
 }
 
-// rotateNow performs immediate log rotation
-func (l *Logger) rotateNow() {
-	// Close current file
-	if l.file != nil {
-		l.file.Close()
+func (h *rotatingHandler) WithGroup(name string) slog.Handler {
+	return &rotatingHandler{Handler: h.Handler.WithGroup(name), logger: h.logger}
+}
+
+// shortSource trims the source attribute to "file.go:line", matching the
+// old log.Lshortfile output.
+func shortSource(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.SourceKey || len(groups) > 0 {
+		return a
 	}
+	if src, ok := a.Value.Any().(*slog.Source); ok {
+		a.Value = slog.StringValue(filepath.Base(src.File) + ":" + strconv.Itoa(src.Line))
+	}
+	return a
+}
 
-	// Perform rotation
-	if err := l.rotator.RotateLog(); err != nil {
-		// Fallback: try to reopen current file
-		l.reopenLogFile()
-		return
+// checkAndRotate rotates before a write if the file has outgrown its
+// maximum size. The size is tracked in memory, so the common case costs no
+// system call.
+func (l *Logger) checkAndRotate() {
+	if l.out.Size() >= l.rotator.Limits().MaxSize {
+		l.rotate(false)
 	}
+}
 
-	// Reopen the new file
-	l.reopenLogFile()
+// rotate rotates the log file, if force is set or the rotator says it is
+// due, and reports whether it did. Rotation holds the writer's lock
+// throughout, so concurrent callers are serialised, the due check is
+// repeated by whichever caller gets the lock second, and no write is
+// attempted on the closed file.
+func (l *Logger) rotate(force bool) (bool, error) {
+	l.out.mu.Lock()
+	if l.out.closed {
+		l.out.mu.Unlock()
+		return false, errLoggerClosed
+	}
+	if !force {
+		if due, err := l.rotator.ShouldRotate(); err != nil || !due {
+			l.out.mu.Unlock()
+			return false, err
+		}
+	}
+
+	l.out.closeLocked()
+	rotateErr := l.rotator.RotateLog()
+	// Reopen the (new or, if rotation failed, current) file
+	openErr := l.out.openLocked()
+	l.out.mu.Unlock()
+
+	// Log outside the lock: these writes go through l.out
+	if openErr != nil {
+		rotations.WithLabelValues("failure").Inc()
+		l.Error("Failed to reopen log file, falling back to stdout", Err(openErr))
+		return false, openErr
+	}
+	if rotateErr != nil {
+		rotations.WithLabelValues("failure").Inc()
+		l.Error("Log rotation failed", Err(rotateErr))
+		return false, rotateErr
+	}
+	rotations.WithLabelValues("success").Inc()
 	l.Info("Log rotation completed")
 	l.logRotationInfo()
+	return true, nil
 }
 
-// reopenLogFile reopens the log file after rotation
-func (l *Logger) reopenLogFile() {
-	file, err := os.OpenFile(l.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		// Fallback to stdout only
-		l.infoLogger.SetOutput(os.Stdout)
-		l.errorLogger.SetOutput(os.Stdout)
-		l.Error("Failed to reopen log file, falling back to stdout")
-		return
+// runRotationScheduler checks for rotation every interval until Close.
+func (l *Logger) runRotationScheduler(interval time.Duration) {
+	defer close(l.schedulerDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopScheduler:
+			return
+		case <-ticker.C:
+			l.rotate(false)
+		}
 	}
-
-	l.file = file
-
-	// Update loggers - comment out multiWriter line if you only want file logging
-	multiWriter := io.MultiWriter(file, os.Stdout)
-	l.infoLogger.SetOutput(multiWriter)
-	l.errorLogger.SetOutput(multiWriter)
 }
 
 // logRotationInfo logs information about rotation settings
@@ -142,8 +313,13 @@ func (l *Logger) logRotationInfo() {
 	if stats, err := l.rotator.GetLogStats(); err == nil {
 		l.Infof("Log stats: %s", stats.String())
 	}
-	l.Infof("Log rotation: max size %s, max age %v, max files %d",
-		LogStats{}.FormatSize(MaxLogFileSize), MaxLogAge, MaxLogFiles)
+	limits := l.rotator.Limits()
+	totalCap := "unlimited"
+	if limits.MaxTotalSize > 0 {
+		totalCap = LogStats{}.FormatSize(limits.MaxTotalSize)
+	}
+	l.Infof("Log rotation: max size %s, max age %v, max files %d, max total size %s",
+		LogStats{}.FormatSize(limits.MaxSize), limits.MaxAge, limits.MaxFiles, totalCap)
 }
 
 // GetStats returns current log statistics (new method)
@@ -151,11 +327,16 @@ func (l *Logger) GetStats() (LogStats, error) {
 	return l.rotator.GetLogStats()
 }
 
+// Limits returns the effective rotation limits.
+func (l *Logger) Limits() RotationLimits {
+	return l.rotator.Limits()
+}
+
 // ForceRotate forces immediate log rotation (new method)
 func (l *Logger) ForceRotate() error {
 	l.Info("Manual log rotation requested")
-	l.rotateNow()
-	return nil
+	_, err := l.rotate(true)
+	return err
 }
 
 // CleanupOldLogs manually triggers cleanup (new method)
@@ -164,11 +345,21 @@ func (l *Logger) CleanupOldLogs() error {
 	return l.rotator.cleanupOldLogs()
 }
 
-// Close closes the logger (new method)
+// Close stops the rotation scheduler, waits for background compression and
+// closes the log file. Records logged afterwards go to stdout only. Close is
+// safe to call more than once.
 func (l *Logger) Close() error {
-	l.Info("Logger shutting down")
-	if l.file != nil {
-		return l.file.Close()
-	}
-	return nil
-}
\ No newline at end of file
+	var err error
+	l.closeOnce.Do(func() {
+		l.Info("Logger shutting down")
+		close(l.stopScheduler)
+		<-l.schedulerDone
+		l.suppress.close()
+		l.rotator.waitForCompression()
+		err = l.out.Close()
+		for _, sink := range l.out.sinks {
+			sink.Close(sinkCloseTimeout)
+		}
+	})
+	return err
+}
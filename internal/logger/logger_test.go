@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentRotationKeepsEveryRecord(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(filepath.Join(dir, "app.log"), Options{
+		Limits: RotationLimits{MaxSize: 4 * 1024, MaxFiles: 1000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const writers, perWriter = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				l.Info("concurrent record", "writer", w, "i", i)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "app.log*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("found %d log files, want the log to have rotated", len(files))
+	}
+	records := 0
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if strings.Contains(scanner.Text(), `"msg":"concurrent record"`) {
+				records++
+			}
+		}
+		f.Close()
+	}
+	if records != writers*perWriter {
+		t.Errorf("found %d records across %d files, want %d", records, len(files), writers*perWriter)
+	}
+}
+
+func TestRotateAfterClose(t *testing.T) {
+	l, err := New(filepath.Join(t.TempDir(), "app.log"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// A second Close must not block on the stopped scheduler
+	if err := l.Close(); err != nil {
+		t.Errorf("second Close() = %v, want nil", err)
+	}
+	if err := l.ForceRotate(); !errors.Is(err, errLoggerClosed) {
+		t.Errorf("ForceRotate() after Close = %v, want %v", err, errLoggerClosed)
+	}
+}
@@ -0,0 +1,23 @@
+// File: internal/logger/metrics.go
+package logger
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	rotations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "log",
+		Name:      "rotations_total",
+		Help:      "Log file rotations by result (success or failure).",
+	}, []string{"result"})
+
+	suppressedRecords = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "log",
+		Name:      "suppressed_records_total",
+		Help:      "Log records not written, by reason (deduplicated or sampled).",
+	}, []string{"reason"})
+)
@@ -0,0 +1,121 @@
+// File: internal/logger/output.go
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// logFile is the writer behind the slog handler: the current log file plus
+// stdout. Writes and file swaps share one mutex, so a write never lands on a
+// descriptor that rotation has closed.
+type logFile struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File // nil if the file could not be (re)opened; stdout still gets every write
+	closed bool
+	size   atomic.Int64 // bytes in the current file, read without the lock
+	tail   hub          // live subscribers, see Logger.Subscribe
+	sinks  sinks        // remote destinations, fixed at construction
+	// redactor masks secrets before a record reaches stdout, the file,
+	// subscribers or any sink.
+	redactor *Redactor
+}
+
+// openLogFile opens path for appending.
+func openLogFile(path string) (*logFile, error) {
+	f := &logFile{path: path, redactor: NewRedactor()}
+	if err := f.openLocked(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *logFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Callers see the length they passed in, not the redacted length
+	n := len(p)
+	p = f.redactor.Redact(p)
+
+	// Comment out the stdout write if you only want file logging
+	os.Stdout.Write(p)
+	f.tail.publish(p)
+	f.sinks.Write(p)
+
+	if f.file == nil {
+		return n, nil
+	}
+	written, err := f.file.Write(p)
+	f.size.Add(int64(written))
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Size returns the number of bytes in the current file.
+func (f *logFile) Size() int64 {
+	return f.size.Load()
+}
+
+// openLocked opens the file at f.path. The caller must hold f.mu or have
+// exclusive access to f.
+func (f *logFile) openLocked() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		f.file = nil
+		f.size.Store(0)
+		return err
+	}
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+	f.file = file
+	f.size.Store(size)
+	return nil
+}
+
+// closeLocked closes the current file. The caller must hold f.mu.
+func (f *logFile) closeLocked() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Close closes the file for good; later writes go to stdout only.
+func (f *logFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return f.closeLocked()
+}
+
+// CheckWritable reports whether records are reaching the log file: the file
+// is open and its directory still accepts new files.
+func (l *Logger) CheckWritable() error {
+	l.out.mu.Lock()
+	closed, open := l.out.closed, l.out.file != nil
+	l.out.mu.Unlock()
+	if closed {
+		return errLoggerClosed
+	}
+	if !open {
+		return fmt.Errorf("log file %s is not open, logging to stdout only", l.out.path)
+	}
+
+	probe, err := os.CreateTemp(filepath.Dir(l.out.path), ".writable-*")
+	if err != nil {
+		return fmt.Errorf("log directory is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
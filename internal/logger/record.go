@@ -0,0 +1,121 @@
+// File: internal/logger/record.go
+package logger
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log line parsed back into the fields used for filtering.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Message   string    `json:"msg"`
+	RequestID string    `json:"request_id,omitempty"`
+	File      string    `json:"file,omitempty"`
+	Line      string    `json:"line"`
+}
+
+// ParseRecord parses a JSON or logfmt log line. Fields it cannot find are
+// left empty; the raw line is always kept.
+func ParseRecord(line string) Record {
+	line = strings.TrimRight(line, "\r\n")
+	rec := Record{Line: line}
+
+	if strings.HasPrefix(line, "{") {
+		var fields struct {
+			Time      time.Time `json:"time"`
+			Level     string    `json:"level"`
+			Message   string    `json:"msg"`
+			RequestID string    `json:"request_id"`
+		}
+		if json.Unmarshal([]byte(line), &fields) == nil {
+			rec.Time, rec.Level, rec.Message, rec.RequestID = fields.Time, fields.Level, fields.Message, fields.RequestID
+		}
+		return rec
+	}
+
+	for key, value := range parseLogfmt(line) {
+		switch key {
+		case "time":
+			rec.Time, _ = time.Parse(time.RFC3339Nano, value)
+		case "level":
+			rec.Level = value
+		case "msg":
+			rec.Message = value
+		case "request_id":
+			rec.RequestID = value
+		}
+	}
+	return rec
+}
+
+// parseLogfmt splits a logfmt line into its top-level key/value pairs.
+func parseLogfmt(line string) map[string]string {
+	fields := make(map[string]string)
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			break
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := 1
+			for end < len(line) && !(line[end] == '"' && line[end-1] != '\\') {
+				end++
+			}
+			if end < len(line) {
+				end++
+			}
+			value, _ = strconv.Unquote(line[:end])
+			line = line[end:]
+		} else if sp := strings.IndexByte(line, ' '); sp >= 0 {
+			value, line = line[:sp], line[sp:]
+		} else {
+			value, line = line, ""
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// Filter selects log records. Zero fields match everything.
+type Filter struct {
+	MinLevel  *Level
+	Contains  string         // substring of the raw line
+	Pattern   *regexp.Regexp // matched against the raw line
+	RequestID string
+	From, To  time.Time
+}
+
+// Match reports whether rec passes every condition of the filter.
+func (f Filter) Match(rec Record) bool {
+	if f.MinLevel != nil {
+		if level, err := ParseLevel(rec.Level); err == nil && level < *f.MinLevel {
+			return false
+		}
+	}
+	if f.RequestID != "" && rec.RequestID != f.RequestID {
+		return false
+	}
+	if !f.From.IsZero() && rec.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && rec.Time.After(f.To) {
+		return false
+	}
+	if f.Contains != "" && !strings.Contains(rec.Line, f.Contains) {
+		return false
+	}
+	if f.Pattern != nil && !f.Pattern.MatchString(rec.Line) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+// File: internal/logger/redact.go
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+)
+
+// Redacted replaces every secret the Redactor finds.
+const Redacted = "[REDACTED]"
+
+// minSecretLen keeps very short configured values from masking unrelated text.
+const minSecretLen = 4
+
+var (
+	// secretParam matches credentials passed as query parameters, e.g. the
+	// "?token=..." the finnhub client appends to request URLs.
+	secretParam = regexp.MustCompile(`(?i)([?&](?:token|api_?key|access_token|secret|password)=)[^&\s"'\\]+`)
+	// secretHeader matches credential headers however they were printed:
+	// "X-Finnhub-Token: v", Go's map[X-Finnhub-Token:[v]], or JSON, where the
+	// quotes may themselves be escaped inside a log record.
+	secretHeader = regexp.MustCompile(`(?i)((?:x-finnhub-token|x-api-key|proxy-authorization|authorization)\\?["']?\s*[:=]\s*\[?\s*\\?["']?(?:(?:bearer|basic|token)\s+)?)[^"'\s,;\]}\\]+`)
+)
+
+// Redactor masks secrets in formatted log records: configured secret values,
+// credential query parameters and credential header values.
+type Redactor struct {
+	mu      sync.RWMutex
+	secrets [][]byte
+}
+
+// NewRedactor returns a Redactor that also masks the given secret values.
+func NewRedactor(secrets ...string) *Redactor {
+	r := &Redactor{}
+	for _, s := range secrets {
+		r.AddSecret(s)
+	}
+	return r
+}
+
+// AddSecret adds a value to mask wherever it appears. Values shorter than
+// four bytes are ignored.
+func (r *Redactor) AddSecret(secret string) {
+	if len(secret) < minSecretLen {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.secrets {
+		if string(s) == secret {
+			return
+		}
+	}
+	r.secrets = append(r.secrets, []byte(secret))
+}
+
+// Redact returns p with every secret masked. p is returned unchanged, without
+// copying, when there is nothing to mask.
+func (r *Redactor) Redact(p []byte) []byte {
+	r.mu.RLock()
+	for _, s := range r.secrets {
+		if bytes.Contains(p, s) {
+			p = bytes.ReplaceAll(p, s, []byte(Redacted))
+		}
+	}
+	r.mu.RUnlock()
+
+	p = secretParam.ReplaceAll(p, []byte("${1}"+Redacted))
+	p = secretHeader.ReplaceAll(p, []byte("${1}"+Redacted))
+	return p
+}
+
+// RedactString is Redact for strings.
+func (r *Redactor) RedactString(s string) string {
+	return string(r.Redact([]byte(s)))
+}
+
+// AddSecret masks secret in every record logged from now on.
+func (l *Logger) AddSecret(secret string) {
+	l.out.redactor.AddSecret(secret)
+}
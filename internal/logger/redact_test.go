@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSecret = "c0ffee1234abcd"
+
+func TestRedact(t *testing.T) {
+	r := NewRedactor(testSecret, "abc") // "abc" is too short to be masked
+	tests := []struct {
+		name, in string
+		// patterns reports whether the value is masked by the built-in
+		// patterns alone, without being configured as a secret.
+		patterns bool
+	}{
+		{"configured secret", "api key is " + testSecret, false},
+		{"token query param", "Get \"https://finnhub.io/api/v1/quote?symbol=AAPL&token=" + testSecret + "\": timeout", true},
+		{"token first param", "GET /api/v1/news?token=" + testSecret, true},
+		{"api_key query param", "https://example.com/x?api_key=" + testSecret + "&a=1", true},
+		{"apikey query param", "https://example.com/x?apiKey=" + testSecret, true},
+		{"header line", "X-Finnhub-Token: " + testSecret, true},
+		{"header lower case", "x-finnhub-token=" + testSecret, true},
+		{"go header map", fmt.Sprint(http.Header{"X-Finnhub-Token": {testSecret}}), true},
+		{"json header", `{"X-Finnhub-Token":"` + testSecret + `"}`, true},
+		{"escaped json header", `{"msg":"{\"X-Finnhub-Token\":\"` + testSecret + `\"}"}`, true},
+		{"bearer authorization", "Authorization: Bearer " + testSecret, true},
+		{"basic authorization", `"Authorization":["Basic ` + testSecret + `"]`, true},
+		{"api key header", "X-Api-Key: " + testSecret, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redactors := []*Redactor{r}
+			if tt.patterns {
+				redactors = append(redactors, NewRedactor())
+			}
+			for _, red := range redactors {
+				got := red.RedactString(tt.in)
+				if strings.Contains(got, testSecret) {
+					t.Errorf("Redact(%q) = %q, secret not masked", tt.in, got)
+				}
+				if !strings.Contains(got, Redacted) {
+					t.Errorf("Redact(%q) = %q, want %s marker", tt.in, got, Redacted)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactLeavesOtherTextAlone(t *testing.T) {
+	r := NewRedactor(testSecret, "abc")
+	for _, in := range []string{
+		"Fetched fresh data for abc",
+		"GET /data/profile?symbol=AAPL&limit=10",
+		"tokens remaining: 5",
+		`{"level":"INFO","msg":"HTTP request","path":"/logs/search"}`,
+	} {
+		if got := r.RedactString(in); got != in {
+			t.Errorf("Redact(%q) = %q, want unchanged", in, got)
+		}
+	}
+}
+
+// captureSink records what reaches a sink.
+type captureSink struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (s *captureSink) Write(p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.Write(p)
+}
+
+func (s *captureSink) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func (s *captureSink) Stats() SinkStats                  { return SinkStats{Name: "capture"} }
+func (s *captureSink) Close(timeout time.Duration) error { return nil }
+
+type leakyConfig struct {
+	Provider string
+	APIKey   string
+}
+
+func TestLoggerNeverWritesSecrets(t *testing.T) {
+	for _, format := range []string{"json", "logfmt"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			sink := &captureSink{}
+			l, err := New(path, Options{Format: format, Sinks: []Sink{sink}, Secrets: []string{testSecret}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sub := l.Subscribe()
+			defer sub.Cancel()
+
+			l.Info("Loaded config", "config", leakyConfig{Provider: "finnhub", APIKey: testSecret})
+			l.Errorf("Failed to fetch: %v", errors.New(`Get "https://finnhub.io/api/v1/quote?symbol=AAPL&token=`+testSecret+`": EOF`))
+			l.Warn("Upstream request", "headers", http.Header{"X-Finnhub-Token": {testSecret}})
+			l.Slog().Info("raw slog", "url", "/api/v1/news?category=general&token="+testSecret)
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var tailed strings.Builder
+			for len(sub.C) > 0 {
+				tailed.Write(<-sub.C)
+			}
+
+			for name, out := range map[string]string{
+				"file": string(file),
+				"sink": sink.String(),
+				"tail": tailed.String(),
+			} {
+				if !strings.Contains(out, "Failed to fetch") {
+					t.Errorf("%s: records missing:\n%s", name, out)
+				}
+				if strings.Contains(out, testSecret) {
+					t.Errorf("%s: secret leaked:\n%s", name, out)
+				}
+			}
+		})
+	}
+}
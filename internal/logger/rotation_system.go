@@ -2,37 +2,81 @@
 package logger
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Default rotation limits, used for any zero field of RotationLimits.
 const (
-	MaxLogFileSize = 10 * 1024 * 1024 // 10MB
-	MaxLogAge      = 5 * 24 * time.Hour // 5 days
-	MaxLogFiles    = 10 // Keep max 10 rotated files
+	DefaultMaxLogFileSize = 10 * 1024 * 1024   // 10MB
+	DefaultMaxLogAge      = 5 * 24 * time.Hour // 5 days
+	DefaultMaxLogFiles    = 10                 // Keep max 10 rotated files
 )
 
+// RotationLimits bounds the size and lifetime of log files.
+type RotationLimits struct {
+	MaxSize  int64         // rotate the current file at this size
+	MaxAge   time.Duration // rotate the current file, and delete rotated files, at this age
+	MaxFiles int           // keep at most this many rotated files
+	// MaxTotalSize caps the current and rotated files together; the oldest
+	// rotated files are deleted until the directory is under it. Zero means
+	// no cap.
+	MaxTotalSize int64
+}
+
+// withDefaults fills zero limits with the package defaults.
+func (l RotationLimits) withDefaults() RotationLimits {
+	if l.MaxSize <= 0 {
+		l.MaxSize = DefaultMaxLogFileSize
+	}
+	if l.MaxAge <= 0 {
+		l.MaxAge = DefaultMaxLogAge
+	}
+	if l.MaxFiles <= 0 {
+		l.MaxFiles = DefaultMaxLogFiles
+	}
+	return l
+}
+
+// compressedExt is appended to rotated files once they are gzip-compressed.
+const compressedExt = ".gz"
+
+// partialExt marks a compressed file that is still being written.
+const partialExt = ".tmp"
+
 // LogRotator handles log file rotation and cleanup
 type LogRotator struct {
 	logFilePath string
-	maxSize     int64
-	maxAge      time.Duration
-	maxFiles    int
+	limits      RotationLimits
+	compress    bool
+	compressing sync.WaitGroup
+	cleanupMu   sync.Mutex // serialises cleanupOldLogs
+
+	pendingMu sync.Mutex
+	pending   map[string]bool // rotated files waiting to be compressed
 }
 
-// NewLogRotator creates a new log rotator
-func NewLogRotator(logFilePath string) *LogRotator {
+// NewLogRotator creates a new log rotator. Zero limits take the defaults.
+func NewLogRotator(logFilePath string, limits RotationLimits) *LogRotator {
 	return &LogRotator{
 		logFilePath: logFilePath,
-		maxSize:     MaxLogFileSize,
-		maxAge:      MaxLogAge,
-		maxFiles:    MaxLogFiles,
+		limits:      limits.withDefaults(),
+		pending:     make(map[string]bool),
 	}
 }
 
+// Limits returns the rotator's effective limits.
+func (lr *LogRotator) Limits() RotationLimits {
+	return lr.limits
+}
+
 // ShouldRotate checks if log file needs rotation
 func (lr *LogRotator) ShouldRotate() (bool, error) {
 	info, err := os.Stat(lr.logFilePath)
@@ -44,12 +88,12 @@ func (lr *LogRotator) ShouldRotate() (bool, error) {
 	}
 
 	// Check file size
-	if info.Size() >= lr.maxSize {
+	if info.Size() >= lr.limits.MaxSize {
 		return true, nil
 	}
 
 	// Check file age
-	if time.Since(info.ModTime()) >= lr.maxAge {
+	if time.Since(info.ModTime()) >= lr.limits.MaxAge {
 		return true, nil
 	}
 
@@ -71,8 +115,7 @@ func (lr *LogRotator) RotateLog() error {
 	}
 
 	// Generate rotated filename with timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	rotatedName := fmt.Sprintf("%s.%s", lr.logFilePath, timestamp)
+	rotatedName := lr.rotatedName(time.Now())
 
 	// Rename current log file
 	if err := os.Rename(lr.logFilePath, rotatedName); err != nil {
@@ -84,51 +127,175 @@ func (lr *LogRotator) RotateLog() error {
 		return fmt.Errorf("failed to create new log file after rotation: %v", err)
 	}
 
-	// Clean up old log files
+	// Compress the rotated file in the background, then clean up, so that
+	// the size budget counts the compressed size rather than the plain file.
+	// Until then cleanup leaves the file alone and does not count it.
+	if lr.compress {
+		lr.setPending(rotatedName, true)
+		lr.compressing.Add(1)
+		go func() {
+			defer lr.compressing.Done()
+			if err := compressFile(rotatedName); err != nil {
+				fmt.Printf("Warning: failed to compress rotated log %s: %v\n", rotatedName, err)
+			}
+			lr.setPending(rotatedName, false)
+			lr.cleanup()
+		}()
+		return nil
+	}
+
+	lr.cleanup()
+	return nil
+}
+
+// cleanup runs cleanupOldLogs, reporting rather than returning a failure,
+// which does not fail the rotation.
+func (lr *LogRotator) cleanup() {
 	if err := lr.cleanupOldLogs(); err != nil {
-		// Log cleanup failure but don't fail the rotation
 		fmt.Printf("Warning: failed to cleanup old logs: %v\n", err)
 	}
+}
 
-	return nil
+// rotatedName returns an unused name for a file rotated at t. A numeric
+// suffix is added if the file was already rotated within the same second.
+func (lr *LogRotator) rotatedName(t time.Time) string {
+	base := fmt.Sprintf("%s.%s", lr.logFilePath, t.Format("2006-01-02_15-04-05"))
+	name := base
+	for i := 1; ; i++ {
+		if !exists(name) && !exists(name+compressedExt) {
+			return name
+		}
+		name = fmt.Sprintf("%s.%d", base, i)
+	}
 }
 
-// createEmptyLogFile creates a new empty log file
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// createEmptyLogFile creates a new empty log file. It is left empty so that
+// every line in it is a record in the logger's format.
 func (lr *LogRotator) createEmptyLogFile() error {
 	file, err := os.Create(lr.logFilePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return file.Close()
+}
+
+// compressFile gzips path to path.gz, keeping its modification time so that
+// cleanup orders it by rotation time, and removes the original.
+func compressFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	gzPath := path + compressedExt
+	tmpPath := gzPath + partialExt
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(path)
+	zw.ModTime = info.ModTime()
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Chtimes(tmpPath, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, gzPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Remove(path)
+}
 
-	// Write initial log entry
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	initialEntry := fmt.Sprintf("INFO: %s Log file created/rotated\n", timestamp)
-	_, err = file.WriteString(initialEntry)
-	return err
+// isPending reports whether path is a rotated file waiting for or being
+// compressed.
+func (lr *LogRotator) isPending(path string) bool {
+	lr.pendingMu.Lock()
+	defer lr.pendingMu.Unlock()
+	return lr.pending[path]
 }
 
-// cleanupOldLogs removes old log files based on age and count limits
-func (lr *LogRotator) cleanupOldLogs() error {
+func (lr *LogRotator) setPending(path string, pending bool) {
+	lr.pendingMu.Lock()
+	defer lr.pendingMu.Unlock()
+	if pending {
+		lr.pending[path] = true
+	} else {
+		delete(lr.pending, path)
+	}
+}
+
+// waitForCompression blocks until background compressions have finished.
+func (lr *LogRotator) waitForCompression() {
+	lr.compressing.Wait()
+}
+
+// rotatedFiles returns the paths of rotated log files, plain or compressed,
+// skipping the current log and partly written compressed files. A plain file
+// may be waiting to be compressed; see isPending.
+func (lr *LogRotator) rotatedFiles() ([]string, error) {
 	logDir := filepath.Dir(lr.logFilePath)
 	logBaseName := filepath.Base(lr.logFilePath)
-
-	// Find all rotated log files
 	pattern := fmt.Sprintf("%s.*", logBaseName)
 	matches, err := filepath.Glob(filepath.Join(logDir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, match := range matches {
+		if match == lr.logFilePath || strings.HasSuffix(match, partialExt) {
+			continue
+		}
+		files = append(files, match)
+	}
+	return files, nil
+}
+
+// cleanupOldLogs removes old log files based on age, count and total size
+// limits. Compressed and plain rotated files are treated alike.
+func (lr *LogRotator) cleanupOldLogs() error {
+	lr.cleanupMu.Lock()
+	defer lr.cleanupMu.Unlock()
+
+	// Find all rotated log files
+	matches, err := lr.rotatedFiles()
 	if err != nil {
 		return err
 	}
 
 	var rotatedFiles []LogFileInfo
-	cutoffTime := time.Now().Add(-lr.maxAge)
+	cutoffTime := time.Now().Add(-lr.limits.MaxAge)
 
 	for _, match := range matches {
-		// Skip the current log file
-		if match == lr.logFilePath {
+		// Counted once compressed, by the cleanup that follows compression
+		if lr.isPending(match) {
 			continue
 		}
-
 		info, err := os.Stat(match)
 		if err != nil {
 			continue
@@ -146,7 +313,13 @@ func (lr *LogRotator) cleanupOldLogs() error {
 		return rotatedFiles[i].ModTime.After(rotatedFiles[j].ModTime)
 	})
 
-	// Remove files that are too old or exceed max count
+	// The current file counts towards the total size budget but is never deleted
+	var totalSize int64
+	if info, err := os.Stat(lr.logFilePath); err == nil {
+		totalSize = info.Size()
+	}
+
+	// Remove files that are too old, exceed max count or overflow the budget
 	for i, file := range rotatedFiles {
 		shouldDelete := false
 
@@ -156,10 +329,18 @@ func (lr *LogRotator) cleanupOldLogs() error {
 		}
 
 		// Delete if exceeds max file count (keep newest files)
-		if i >= lr.maxFiles {
+		if i >= lr.limits.MaxFiles {
 			shouldDelete = true
 		}
 
+		// Delete if keeping it would exceed the total size budget (keep newest files)
+		if !shouldDelete && lr.limits.MaxTotalSize > 0 && totalSize+file.Size > lr.limits.MaxTotalSize {
+			shouldDelete = true
+		}
+		if !shouldDelete {
+			totalSize += file.Size
+		}
+
 		if shouldDelete {
 			if err := os.Remove(file.Path); err != nil {
 				fmt.Printf("Warning: failed to remove old log file %s: %v\n", file.Path, err)
@@ -179,31 +360,6 @@ type LogFileInfo struct {
 	Size    int64
 }
 
-// StartRotationScheduler starts a goroutine that checks for log rotation periodically
-func (lr *LogRotator) StartRotationScheduler(interval time.Duration, onRotate func()) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				if shouldRotate, err := lr.ShouldRotate(); err != nil {
-					fmt.Printf("Error checking log rotation: %v\n", err)
-				} else if shouldRotate {
-					if err := lr.RotateLog(); err != nil {
-						fmt.Printf("Error rotating log: %v\n", err)
-					} else {
-						fmt.Printf("Log rotated successfully at %s\n", time.Now().Format("2006-01-02 15:04:05"))
-						if onRotate != nil {
-							onRotate()
-						}
-					}
-				}
-			}
-		}
-	}()
-}
-
 // GetLogStats returns statistics about log files
 func (lr *LogRotator) GetLogStats() (LogStats, error) {
 	stats := LogStats{}
@@ -215,20 +371,18 @@ func (lr *LogRotator) GetLogStats() (LogStats, error) {
 	}
 
 	// Count rotated files
-	logDir := filepath.Dir(lr.logFilePath)
-	logBaseName := filepath.Base(lr.logFilePath)
-	pattern := fmt.Sprintf("%s.*", logBaseName)
-	matches, err := filepath.Glob(filepath.Join(logDir, pattern))
+	matches, err := lr.rotatedFiles()
 	if err != nil {
 		return stats, err
 	}
 
 	for _, match := range matches {
-		if match != lr.logFilePath {
-			stats.RotatedCount++
-			if info, err := os.Stat(match); err == nil {
-				stats.TotalSize += info.Size()
-			}
+		stats.RotatedCount++
+		if strings.HasSuffix(match, compressedExt) {
+			stats.CompressedCount++
+		}
+		if info, err := os.Stat(match); err == nil {
+			stats.TotalSize += info.Size()
 		}
 	}
 
@@ -238,10 +392,11 @@ func (lr *LogRotator) GetLogStats() (LogStats, error) {
 
 // LogStats holds statistics about log files
 type LogStats struct {
-	CurrentSize   int64
-	CurrentAge    time.Duration
-	RotatedCount  int
-	TotalSize     int64
+	CurrentSize     int64
+	CurrentAge      time.Duration
+	RotatedCount    int // includes compressed files
+	CompressedCount int
+	TotalSize       int64
 }
 
 // FormatSize formats bytes to human readable format
@@ -260,9 +415,10 @@ func (s LogStats) FormatSize(bytes int64) string {
 
 // String returns a formatted string of log statistics
 func (s LogStats) String() string {
-	return fmt.Sprintf("Current: %s (age: %v), Rotated files: %d, Total size: %s",
+	return fmt.Sprintf("Current: %s (age: %v), Rotated files: %d (%d compressed), Total size: %s",
 		s.FormatSize(s.CurrentSize),
 		s.CurrentAge.Round(time.Minute),
 		s.RotatedCount,
+		s.CompressedCount,
 		s.FormatSize(s.TotalSize))
-}
\ No newline at end of file
+}
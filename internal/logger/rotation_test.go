@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rotateN writes size bytes to the log file and rotates it n times, each
+// rotated file an hour newer than the last.
+func rotateN(t *testing.T, lr *LogRotator, n, size int) {
+	t.Helper()
+	line := strings.Repeat("x", 99) + "\n"
+	for i := 0; i < n; i++ {
+		if err := os.WriteFile(lr.logFilePath, []byte(strings.Repeat(line, size/len(line))), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mod := time.Now().Add(time.Duration(i-n) * time.Hour)
+		if err := os.Chtimes(lr.logFilePath, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+		if err := lr.RotateLog(); err != nil {
+			t.Fatalf("RotateLog: %v", err)
+		}
+	}
+	lr.waitForCompression()
+}
+
+func TestRotateKeepsCompressedFilesUnderBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	// Each rotated file is 10KB plain but well under 1KB compressed
+	lr := NewLogRotator(path, RotationLimits{MaxTotalSize: 15 * 1024})
+	lr.compress = true
+
+	rotateN(t, lr, 3, 10*1024)
+
+	files, err := lr.rotatedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("kept %d rotated files, want all 3: %v", len(files), files)
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f, compressedExt) {
+			t.Errorf("%s was not compressed", f)
+		}
+	}
+}
+
+func TestRotateDeletesOldestOverBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lr := NewLogRotator(path, RotationLimits{MaxTotalSize: 25 * 1024})
+
+	rotateN(t, lr, 4, 10*1024)
+
+	files, err := lr.rotatedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("kept %d rotated files, want the newest 2: %v", len(files), files)
+	}
+	var oldest time.Time
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
+	}
+	if time.Since(oldest) > 2*time.Hour+time.Minute {
+		t.Errorf("oldest kept file is from %v ago, want the newest two kept", time.Since(oldest).Round(time.Hour))
+	}
+}
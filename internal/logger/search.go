@@ -0,0 +1,148 @@
+// File: internal/logger/search.go
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Search pagination limits.
+const (
+	DefaultSearchLimit = 100
+	MaxSearchLimit     = 1000
+)
+
+// SearchResult is one page of matching records, oldest first.
+type SearchResult struct {
+	Records []Record `json:"records"`
+	// NextOffset is the offset of the next page, or -1 if there is none.
+	NextOffset int `json:"nextOffset"`
+	// FilesScanned counts the files read, plain and compressed.
+	FilesScanned int `json:"filesScanned"`
+}
+
+// Search scans the rotated files (plain and gzip-compressed) and then the
+// current file for records matching f, skipping the first offset matches
+// and returning at most limit.
+func (l *Logger) Search(f Filter, offset, limit int) (SearchResult, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	paths, err := l.searchFiles(f)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	result := SearchResult{Records: []Record{}, NextOffset: -1}
+	matched := 0
+	for _, path := range paths {
+		result.FilesScanned++
+		done, err := scanLogFile(path, func(rec Record) bool {
+			if !f.Match(rec) {
+				return true
+			}
+			matched++
+			if matched <= offset {
+				return true
+			}
+			if len(result.Records) == limit {
+				result.NextOffset = offset + limit
+				return false
+			}
+			rec.File = path
+			result.Records = append(result.Records, rec)
+			return true
+		})
+		if err != nil {
+			return result, err
+		}
+		if done {
+			break
+		}
+	}
+	return result, nil
+}
+
+// searchFiles lists the log files in chronological order, leaving out
+// rotated files last written before the start of the filter's time range.
+func (l *Logger) searchFiles(f Filter) ([]string, error) {
+	rotated, err := l.rotator.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		path string
+		mod  int64
+	}
+	var files []candidate
+	for _, path := range rotated {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) && !strings.HasSuffix(path, compressedExt) {
+			// Compressed since it was listed; scanLogFile reads the .gz
+			info, err = os.Stat(path + compressedExt)
+		}
+		if err != nil {
+			continue
+		}
+		if !f.From.IsZero() && info.ModTime().Before(f.From) {
+			continue
+		}
+		files = append(files, candidate{path, info.ModTime().UnixNano()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod < files[j].mod })
+
+	paths := make([]string, 0, len(files)+1)
+	for _, c := range files {
+		paths = append(paths, c.path)
+	}
+	return append(paths, l.rotator.logFilePath), nil
+}
+
+// scanLogFile calls fn for each record in path, decompressing .gz files,
+// until fn returns false. It reports whether fn stopped the scan. A plain
+// rotated file compressed since it was listed is read from its .gz instead.
+func scanLogFile(path string, fn func(Record) bool) (bool, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) && !strings.HasSuffix(path, compressedExt) {
+		path += compressedExt
+		file, err = os.Open(path)
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil // rotated or cleaned up since it was listed
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, compressedExt) {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return false, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if !fn(ParseRecord(scanner.Text())) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearchFindsJustRotatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// A file rotated a moment ago, still waiting for its compression
+	rotated := l.rotator.rotatedName(time.Now())
+	line := `{"time":"` + time.Now().Add(-time.Second).UTC().Format(time.RFC3339Nano) + `","level":"ERROR","msg":"incident just before rotation"}` + "\n"
+	if err := os.WriteFile(rotated, []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l.rotator.setPending(rotated, true)
+
+	search := func() []Record {
+		t.Helper()
+		result, err := l.Search(Filter{Contains: "incident just before rotation"}, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Records
+	}
+
+	if got := search(); len(got) != 1 || got[0].File != rotated {
+		t.Fatalf("Search() before compression = %+v, want the record from %s", got, rotated)
+	}
+
+	// Once compressed, it is found in the .gz
+	if err := compressFile(rotated); err != nil {
+		t.Fatal(err)
+	}
+	l.rotator.setPending(rotated, false)
+	if got := search(); len(got) != 1 || got[0].File != rotated+compressedExt {
+		t.Fatalf("Search() after compression = %+v, want the record from %s", got, rotated+compressedExt)
+	}
+}
+
+func TestScanLogFileFollowsCompression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.2026-10-18_12-00-00")
+	if err := os.WriteFile(path, []byte(`{"level":"INFO","msg":"one"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Listed as plain, then compressed before it was read
+	if err := compressFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []string
+	if _, err := scanLogFile(path, func(r Record) bool {
+		msgs = append(msgs, r.Message)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || msgs[0] != "one" {
+		t.Errorf("scanned %q, want [one]", msgs)
+	}
+}
@@ -0,0 +1,125 @@
+// File: internal/logger/sink.go
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink ships log records somewhere other than the log file.
+type Sink interface {
+	// Write queues one formatted record for delivery. It must not block and
+	// must not retain p after returning.
+	Write(p []byte)
+	// Stats returns the sink's delivery counters.
+	Stats() SinkStats
+	// Close delivers what is still queued, waiting at most timeout, and
+	// stops the sink.
+	Close(timeout time.Duration) error
+}
+
+// SinkStats reports how a sink's deliveries have gone.
+type SinkStats struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Delivered int64  `json:"delivered"`
+	Dropped   int64  `json:"dropped"` // discarded because the buffer was full
+	Failed    int64  `json:"failed"`  // discarded after delivery kept failing
+	Retries   int64  `json:"retries"`
+	Pending   int    `json:"pending"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// sinkCloseTimeout bounds how long Logger.Close waits for each sink to flush.
+const sinkCloseTimeout = 5 * time.Second
+
+// sinkQueue is the bounded buffer and counters shared by the sink
+// implementations. Records are copied into the buffer by Write and
+// consumed by the sink's own goroutine, so a slow destination only ever
+// costs dropped records, never a blocked log call.
+type sinkQueue struct {
+	name, kind string
+	records    chan []byte
+	closing    chan struct{}
+	done       chan struct{}
+	closeOnce  sync.Once
+
+	delivered, dropped, failed, retries atomic.Int64
+
+	mu      sync.Mutex
+	lastErr string
+}
+
+func newSinkQueue(name, kind string, size int) *sinkQueue {
+	return &sinkQueue{
+		name:    name,
+		kind:    kind,
+		records: make(chan []byte, size),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (q *sinkQueue) Write(p []byte) {
+	select {
+	case <-q.closing:
+		q.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case q.records <- append([]byte(nil), p...):
+	default:
+		q.dropped.Add(1)
+	}
+}
+
+func (q *sinkQueue) setError(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastErr = err.Error()
+}
+
+func (q *sinkQueue) Stats() SinkStats {
+	q.mu.Lock()
+	lastErr := q.lastErr
+	q.mu.Unlock()
+	return SinkStats{
+		Name:      q.name,
+		Type:      q.kind,
+		Delivered: q.delivered.Load(),
+		Dropped:   q.dropped.Load(),
+		Failed:    q.failed.Load(),
+		Retries:   q.retries.Load(),
+		Pending:   len(q.records),
+		LastError: lastErr,
+	}
+}
+
+// shutdown signals the worker to flush and stop, and waits up to timeout.
+func (q *sinkQueue) shutdown(timeout time.Duration) {
+	q.closeOnce.Do(func() { close(q.closing) })
+	select {
+	case <-q.done:
+	case <-time.After(timeout):
+	}
+}
+
+// sinks fans records out to every configured Sink.
+type sinks []Sink
+
+func (s sinks) Write(p []byte) {
+	for _, sink := range s {
+		sink.Write(p)
+	}
+}
+
+// SinkStats returns the delivery counters of every configured sink.
+func (l *Logger) SinkStats() []SinkStats {
+	stats := make([]SinkStats, 0, len(l.out.sinks))
+	for _, sink := range l.out.sinks {
+		stats = append(stats, sink.Stats())
+	}
+	return stats
+}
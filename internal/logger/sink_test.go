@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchServer records the JSON arrays POSTed to it and answers with the
+// given statuses in turn, then 200.
+type batchServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	batches  [][]json.RawMessage
+	auth     []string
+	statuses []int
+}
+
+func newBatchServer(t *testing.T, statuses ...int) *batchServer {
+	s := &batchServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.auth = append(s.auth, r.Header.Get("Authorization"))
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			w.WriteHeader(status)
+			return
+		}
+		var batch []json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("batch is not a JSON array: %v", err)
+		}
+		s.batches = append(s.batches, batch)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestHTTPSinkBatches(t *testing.T) {
+	srv := newBatchServer(t)
+	sink := NewHTTPSink(HTTPSinkConfig{
+		URL:           srv.URL,
+		Authorization: "Bearer sink-token",
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+	})
+	for i := 0; i < 6; i++ {
+		sink.Write([]byte(fmt.Sprintf(`{"msg":"record %d"}`+"\n", i)))
+	}
+	sink.Write([]byte("level=INFO msg=logfmt\n"))
+	sink.Close(5 * time.Second)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var sizes []int
+	for _, b := range srv.batches {
+		sizes = append(sizes, len(b))
+	}
+	if fmt.Sprint(sizes) != "[3 3 1]" {
+		t.Fatalf("batch sizes = %v, want [3 3 1]", sizes)
+	}
+	if got := string(srv.batches[0][0]); got != `{"msg":"record 0"}` {
+		t.Errorf("JSON record sent as %s, want it embedded as an object", got)
+	}
+	if got := string(srv.batches[2][0]); got != `"level=INFO msg=logfmt"` {
+		t.Errorf("logfmt record sent as %s, want it as a string", got)
+	}
+	for _, a := range srv.auth {
+		if a != "Bearer sink-token" {
+			t.Errorf("Authorization = %q, want the configured value", a)
+		}
+	}
+	if st := sink.Stats(); st.Delivered != 7 || st.Failed != 0 || st.Dropped != 0 {
+		t.Errorf("Stats() = %+v, want 7 delivered", st)
+	}
+}
+
+func TestHTTPSinkRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		maxRetries  int
+		delivered   int64
+		failed      int64
+		retries     int64
+		wantLastErr string
+	}{
+		{"retries a server error", []int{503}, 3, 1, 0, 1, "503"},
+		{"retries too many requests", []int{429}, 3, 1, 0, 1, "429"},
+		{"gives up after max retries", []int{500, 500}, 1, 0, 1, 1, "500"},
+		{"zero retries sends once", []int{503}, 0, 0, 1, 0, "503"},
+		{"does not retry a client error", []int{400}, 3, 0, 1, 0, "400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newBatchServer(t, tt.statuses...)
+			sink := NewHTTPSink(HTTPSinkConfig{
+				URL:        srv.URL,
+				BatchSize:  1,
+				MaxRetries: intPtr(tt.maxRetries),
+			})
+			sink.Write([]byte(`{"msg":"x"}`))
+			deadline := time.Now().Add(5 * time.Second)
+			for st := sink.Stats(); st.Delivered+st.Failed == 0 && time.Now().Before(deadline); st = sink.Stats() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			sink.Close(time.Second)
+
+			st := sink.Stats()
+			if st.Delivered != tt.delivered || st.Failed != tt.failed || st.Retries != tt.retries {
+				t.Errorf("Stats() = %+v, want %d delivered, %d failed, %d retries", st, tt.delivered, tt.failed, tt.retries)
+			}
+			if !strings.Contains(st.LastError, tt.wantLastErr) {
+				t.Errorf("LastError = %q, want it to mention %s", st.LastError, tt.wantLastErr)
+			}
+		})
+	}
+}
+
+func TestHTTPSinkDropsWhenFull(t *testing.T) {
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		received <- struct{}{}
+		<-release
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(HTTPSinkConfig{URL: srv.URL, BatchSize: 1, BufferSize: 2})
+	sink.Write([]byte(`{"msg":"in flight"}`))
+	<-received // the worker is now blocked delivering the first record
+	for i := 0; i < 5; i++ {
+		sink.Write([]byte(`{"msg":"queued"}`))
+	}
+	if st := sink.Stats(); st.Pending != 2 || st.Dropped != 3 {
+		t.Errorf("Stats() while blocked = %+v, want 2 pending and 3 dropped", st)
+	}
+
+	close(release)
+	sink.Close(5 * time.Second)
+	sink.Write([]byte(`{"msg":"after close"}`))
+	if st := sink.Stats(); st.Delivered != 3 || st.Dropped != 4 {
+		t.Errorf("Stats() after close = %+v, want 3 delivered and 4 dropped", st)
+	}
+}
+
+const syslogTestRecord = `{"time":"2026-10-18T12:00:00.5Z","level":"WARN","msg":"disk almost full"}`
+
+func TestSyslogSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	sink := NewSyslogSink(SyslogConfig{Network: "udp", Address: pc.LocalAddr().String(), Facility: intPtr(0)})
+	defer sink.Close(time.Second)
+	sink.Write([]byte(syslogTestRecord + "\n"))
+
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostname, _ := os.Hostname()
+	// Facility 0 (kern) and severity 4 (warning)
+	want := fmt.Sprintf("<4>1 2026-10-18T12:00:00.5Z %s stockspotlight %d - - %s", hostname, os.Getpid(), syslogTestRecord)
+	if got := string(buf[:n]); got != want {
+		t.Errorf("message = %q\nwant      %q", got, want)
+	}
+}
+
+func TestSyslogSinkTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	sink := NewSyslogSink(SyslogConfig{Network: "tcp", Address: ln.Addr().String(), AppName: "test"})
+	sink.Write([]byte(syslogTestRecord + "\n"))
+	sink.Write([]byte("time=2026-10-18T12:00:01Z level=ERROR msg=failed\n"))
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+
+	wantPrefixes := []string{"<132>1 2026-10-18T12:00:00.5Z ", "<131>1 2026-10-18T12:00:01Z "}
+	for _, prefix := range wantPrefixes {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			t.Fatalf("frame does not start with an octet count: %q", length)
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			t.Fatal(err)
+		}
+		// local0 by default: 16*8 + severity
+		if !strings.HasPrefix(string(msg), prefix) {
+			t.Errorf("message = %q, want prefix %q", msg, prefix)
+		}
+	}
+	sink.Close(time.Second)
+	if st := sink.Stats(); st.Delivered != 2 {
+		t.Errorf("Stats() = %+v, want 2 delivered", st)
+	}
+}
@@ -0,0 +1,301 @@
+// File: internal/logger/suppress.go
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampling limits how often a single call site below Warn is logged: in
+// every Interval the first Initial records are kept, then every
+// Thereafter-th. A zero Initial disables sampling.
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Interval   time.Duration
+}
+
+// SuppressionStats counts records that were not written.
+type SuppressionStats struct {
+	Deduplicated int64 `json:"deduplicated"`
+	Sampled      int64 `json:"sampled"`
+	// BySource counts suppressed records per call site ("file.go:line").
+	BySource map[string]int64 `json:"bySource"`
+}
+
+// KeyRepeatedMsg carries the message a repeat summary refers to.
+const KeyRepeatedMsg = "repeated_msg"
+
+// suppressor holds the dedupe and sampling state shared by a Logger's
+// handlers, including those derived with With.
+type suppressor struct {
+	window   time.Duration
+	sampling Sampling
+	root     slog.Handler // writes repeat summaries
+
+	mu       sync.Mutex
+	repeats  map[repeatKey]*repeat
+	sites    map[uintptr]*siteSample
+	bySource map[string]int64
+
+	deduplicated, sampled atomic.Int64
+
+	stop, done chan struct{}
+}
+
+// repeatKey identifies a message: its call site, level, text and record
+// attributes. Attributes added with With, such as request_id, are not part
+// of it, so the same failure seen by different requests is one message.
+type repeatKey struct {
+	pc    uintptr
+	level slog.Level
+	msg   string
+	attrs string
+}
+
+type repeat struct {
+	first time.Time
+	count int // suppressed since first
+}
+
+type siteSample struct {
+	start time.Time
+	n     int
+}
+
+func newSuppressor(window time.Duration, sampling Sampling, root slog.Handler) *suppressor {
+	if sampling.Initial > 0 {
+		if sampling.Thereafter <= 0 {
+			sampling.Thereafter = 100
+		}
+		if sampling.Interval <= 0 {
+			sampling.Interval = time.Second
+		}
+	}
+	s := &suppressor{
+		window:   window,
+		sampling: sampling,
+		root:     root,
+		repeats:  make(map[repeatKey]*repeat),
+		sites:    make(map[uintptr]*siteSample),
+		bySource: make(map[string]int64),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if s.enabled() {
+		go s.run()
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+func (s *suppressor) enabled() bool {
+	return s.window > 0 || s.sampling.Initial > 0
+}
+
+// allow reports whether r should be written. It may first write a summary
+// of the repeats of r's message that ended with the previous window.
+func (s *suppressor) allow(r slog.Record) bool {
+	if !s.enabled() {
+		return true
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	if s.sampling.Initial > 0 && r.Level < slog.LevelWarn {
+		site := s.sites[r.PC]
+		if site == nil || now.Sub(site.start) >= s.sampling.Interval {
+			site = &siteSample{start: now}
+			s.sites[r.PC] = site
+		}
+		site.n++
+		if over := site.n - s.sampling.Initial; over > 0 && over%s.sampling.Thereafter != 0 {
+			s.bySource[source(r.PC)]++
+			s.mu.Unlock()
+			s.sampled.Add(1)
+			suppressedRecords.WithLabelValues("sampled").Inc()
+			return false
+		}
+	}
+
+	var summary *slog.Record
+	if s.window > 0 {
+		key := repeatKey{pc: r.PC, level: r.Level, msg: r.Message, attrs: attrKey(r)}
+		if rep := s.repeats[key]; rep != nil {
+			if now.Sub(rep.first) < s.window {
+				rep.count++
+				s.bySource[source(r.PC)]++
+				s.mu.Unlock()
+				s.deduplicated.Add(1)
+				suppressedRecords.WithLabelValues("deduplicated").Inc()
+				return false
+			}
+			if rep.count > 0 {
+				sr := repeatSummary(key, rep.count)
+				summary = &sr
+			}
+		}
+		s.repeats[key] = &repeat{first: now}
+	}
+	s.mu.Unlock()
+
+	if summary != nil {
+		_ = s.root.Handle(context.Background(), *summary)
+	}
+	return true
+}
+
+// run writes the summaries of windows that have ended and forgets idle
+// messages and call sites, until stopped.
+func (s *suppressor) run() {
+	defer close(s.done)
+	interval := s.window
+	if interval <= 0 || (s.sampling.Initial > 0 && s.sampling.Interval < interval) {
+		interval = s.sampling.Interval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			s.flush(true)
+			return
+		case <-ticker.C:
+			s.flush(false)
+		}
+	}
+}
+
+// flush writes summaries for windows that have ended, or for all of them
+// if all is set.
+func (s *suppressor) flush(all bool) {
+	now := time.Now()
+	var summaries []slog.Record
+
+	s.mu.Lock()
+	for key, rep := range s.repeats {
+		if !all && now.Sub(rep.first) < s.window {
+			continue
+		}
+		if rep.count > 0 {
+			summaries = append(summaries, repeatSummary(key, rep.count))
+		}
+		delete(s.repeats, key)
+	}
+	for pc, site := range s.sites {
+		if now.Sub(site.start) >= s.sampling.Interval {
+			delete(s.sites, pc)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, r := range summaries {
+		_ = s.root.Handle(context.Background(), r)
+	}
+}
+
+// close writes any pending summaries and stops the flusher.
+func (s *suppressor) close() {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	close(s.stop)
+	<-s.done
+}
+
+func (s *suppressor) stats() SuppressionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bySource := make(map[string]int64, len(s.bySource))
+	for k, v := range s.bySource {
+		bySource[k] = v
+	}
+	return SuppressionStats{
+		Deduplicated: s.deduplicated.Load(),
+		Sampled:      s.sampled.Load(),
+		BySource:     bySource,
+	}
+}
+
+func repeatSummary(key repeatKey, count int) slog.Record {
+	r := slog.NewRecord(time.Now(), key.level, fmt.Sprintf("previous message repeated %d times", count), key.pc)
+	r.AddAttrs(slog.String(KeyRepeatedMsg, key.msg))
+	return r
+}
+
+// attrKey flattens r's attributes into a comparable string, leaving out the
+// duration, which differs on every call.
+func attrKey(r slog.Record) string {
+	if r.NumAttrs() == 0 {
+		return ""
+	}
+	var b strings.Builder
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == KeyDurationMS {
+			return true
+		}
+		b.WriteString(a.Key)
+		b.WriteByte('=')
+		b.WriteString(a.Value.String())
+		b.WriteByte(';')
+		return true
+	})
+	return b.String()
+}
+
+// source formats pc as "file.go:line".
+func source(pc uintptr) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return "unknown"
+	}
+	return filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+}
+
+type unsuppressedKey struct{}
+
+// Unsuppressed returns a copy of ctx whose records are never deduplicated or
+// sampled, for logs that must keep one line per event, such as the access
+// log. Pass it to the slog.Logger's Context or LogAttrs methods.
+func Unsuppressed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unsuppressedKey{}, true)
+}
+
+// suppressingHandler drops records that the suppressor deduplicates or
+// samples away, unless they were logged with an Unsuppressed context.
+type suppressingHandler struct {
+	slog.Handler
+	s *suppressor
+}
+
+func (h *suppressingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx.Value(unsuppressedKey{}) == nil && !h.s.allow(r) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *suppressingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &suppressingHandler{Handler: h.Handler.WithAttrs(attrs), s: h.s}
+}
+
+func (h *suppressingHandler) WithGroup(name string) slog.Handler {
+	return &suppressingHandler{Handler: h.Handler.WithGroup(name), s: h.s}
+}
+
+// SuppressionStats returns how many records were deduplicated or sampled
+// away, in total and per call site.
+func (l *Logger) SuppressionStats() SuppressionStats {
+	return l.suppress.stats()
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHandler keeps the messages of the records it handles.
+type recordingHandler struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgs = append(h.msgs, r.Message)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestDedupeWindow(t *testing.T) {
+	type step struct {
+		msg   string
+		attrs []any
+		// expire ends the window of every message seen so far first.
+		expire bool
+		want   bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		// summaries are written before the record that follows the window,
+		// or on close.
+		summaries []string
+	}{
+		{
+			name:      "repeats within the window are dropped",
+			steps:     []step{{msg: "a", want: true}, {msg: "a"}, {msg: "a"}},
+			summaries: []string{"previous message repeated 2 times"},
+		},
+		{
+			name:  "different messages are kept",
+			steps: []step{{msg: "a", want: true}, {msg: "b", want: true}},
+		},
+		{
+			name: "different attributes are different messages",
+			steps: []step{
+				{msg: "a", attrs: []any{"ticker", "AAPL"}, want: true},
+				{msg: "a", attrs: []any{"ticker", "MSFT"}, want: true},
+			},
+		},
+		{
+			name: "durations are ignored",
+			steps: []step{
+				{msg: "a", attrs: []any{KeyDurationMS, 12}, want: true},
+				{msg: "a", attrs: []any{KeyDurationMS, 15}},
+			},
+			summaries: []string{"previous message repeated 1 times"},
+		},
+		{
+			name: "next window starts with a summary",
+			steps: []step{
+				{msg: "a", want: true},
+				{msg: "a"},
+				{msg: "a", expire: true, want: true},
+			},
+			summaries: []string{"previous message repeated 1 times"},
+		},
+		{
+			name:  "no summary without repeats",
+			steps: []step{{msg: "a", want: true}, {msg: "a", expire: true, want: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &recordingHandler{}
+			s := newSuppressor(time.Hour, Sampling{}, root)
+			for i, st := range tt.steps {
+				if st.expire {
+					s.mu.Lock()
+					for _, rep := range s.repeats {
+						rep.first = rep.first.Add(-s.window)
+					}
+					s.mu.Unlock()
+				}
+				r := slog.NewRecord(time.Now(), slog.LevelError, st.msg, 0)
+				r.Add(st.attrs...)
+				if got := s.allow(r); got != st.want {
+					t.Errorf("step %d: allow(%q) = %v, want %v", i, st.msg, got, st.want)
+				}
+			}
+			s.close()
+
+			if !reflect.DeepEqual(root.msgs, tt.summaries) {
+				t.Errorf("summaries = %q, want %q", root.msgs, tt.summaries)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// File: internal/logger/tail.go
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// tailBuffer is how many records a subscriber may fall behind before new
+// records are dropped for it.
+const tailBuffer = 256
+
+// Subscription receives every record written by the logger from the time it
+// was created until it is cancelled.
+type Subscription struct {
+	C       <-chan []byte
+	c       chan []byte
+	dropped atomic.Int64
+	hub     *hub
+}
+
+// Dropped returns how many records were skipped because the subscriber
+// could not keep up.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Cancel stops delivery and releases the subscription.
+func (s *Subscription) Cancel() {
+	s.hub.remove(s)
+}
+
+// hub fans written records out to subscribers without ever blocking the writer.
+type hub struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func (h *hub) add() *Subscription {
+	c := make(chan []byte, tailBuffer)
+	s := &Subscription{C: c, c: c, hub: h}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs == nil {
+		h.subs = make(map[*Subscription]struct{})
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+func (h *hub) remove(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs, s)
+}
+
+// publish delivers a copy of p to every subscriber with room for it.
+func (h *hub) publish(p []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subs) == 0 {
+		return
+	}
+	line := append([]byte(nil), p...)
+	for s := range h.subs {
+		select {
+		case s.c <- line:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe returns a Subscription that receives each record as it is
+// written. Callers must Cancel it when done.
+func (l *Logger) Subscribe() *Subscription {
+	return l.out.tail.add()
+}
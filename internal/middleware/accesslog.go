@@ -0,0 +1,45 @@
+// File: internal/middleware/accesslog.go
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+)
+
+// AccessLog writes one log record per request with its method, path,
+// status, response size, duration, remote address and user agent. It uses
+// the request-scoped logger, so it should run inside RequestID. Server
+// errors are logged at Error and client errors at Warn. Identical requests
+// each get their own line: the records are exempt from deduplication and
+// sampling.
+func AccessLog() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := newResponseRecorder(w)
+
+			next.ServeHTTP(rec, r)
+
+			level := slog.LevelInfo
+			switch status := rec.Status(); {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+
+			logger.FromContext(r.Context()).LogAttrs(logger.Unsuppressed(r.Context()), level, "HTTP request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.Status()),
+				slog.Int64("bytes", rec.bytes),
+				logger.Duration(time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("user_agent", r.UserAgent()),
+			)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+)
+
+func TestAccessLogWritesEveryIdenticalRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := logger.New(path, logger.Options{
+		DedupeWindow: time.Hour,
+		Sampling:     logger.Sampling{Initial: 1, Thereafter: 100, Interval: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := AccessLog()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	const requests = 5
+	for i := 0; i < requests; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/data/gainers", nil)
+		r = r.WithContext(logger.WithContext(r.Context(), l.Slog()))
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), `"msg":"HTTP request"`); got != requests {
+		t.Errorf("found %d access lines, want %d:\n%s", got, requests, data)
+	}
+	if strings.Contains(string(data), "repeated") {
+		t.Errorf("access lines were summarised as repeats:\n%s", data)
+	}
+}
@@ -0,0 +1,229 @@
+// File: internal/middleware/auth.go
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/audit"
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AuthOptions configures Auth.
+type AuthOptions struct {
+	// Tokens maps each accepted bearer token to the name of its holder.
+	Tokens map[string]string
+	// Users maps each basic auth username to its bcrypt password hash.
+	Users map[string]string
+	// MaxFailures failed attempts from one client within Lockout block the
+	// client until the window has passed.
+	MaxFailures int
+	Lockout     time.Duration
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header
+	// names the client, so that clients behind them are told apart.
+	TrustedProxies []netip.Prefix
+	// Denied, if set, is called for each failed attempt with the identity
+	// the request claimed and why it was rejected; the attempt that blocks
+	// the client is reported with ReasonLockedOut. Requests without any
+	// credentials, and attempts while blocked, are not reported.
+	Denied func(r *http.Request, claimed, reason string)
+}
+
+// Reasons passed to AuthOptions.Denied.
+const (
+	ReasonInvalidToken    = "invalid token"
+	ReasonInvalidPassword = "invalid username or password"
+	ReasonLockedOut       = "too many failed attempts"
+)
+
+// dummyHash is compared against when the username is unknown, so that a
+// wrong username takes as long to reject as a wrong password.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("stockspotlight"), bcrypt.DefaultCost)
+
+// Auth requires a valid bearer token or basic auth password on every
+// request. The identity it authenticates, "token:<name>" or the username, is
+// stored with audit.WithIdentity and added to the request-scoped logger as
+// actor. Clients are told apart by ClientIP. Auth should run inside
+// RequestID and AccessLog to have its rejections logged with them.
+func Auth(opts AuthOptions) Middleware {
+	tokens := make(map[[sha256.Size]byte]string, len(opts.Tokens))
+	for token, name := range opts.Tokens {
+		tokens[sha256.Sum256([]byte(token))] = name
+	}
+	failures := newFailureLimiter(opts.MaxFailures, opts.Lockout)
+
+	var challenges []string
+	if len(opts.Tokens) > 0 {
+		challenges = append(challenges, `Bearer realm="stockspotlight admin"`)
+	}
+	if len(opts.Users) > 0 {
+		challenges = append(challenges, `Basic realm="stockspotlight admin", charset="UTF-8"`)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := ClientIP(r, opts.TrustedProxies)
+			if wait, blocked := failures.blocked(client); blocked {
+				// Not audited: a blocked client could otherwise force a
+				// durable write per request
+				authFailures.WithLabelValues("blocked").Inc()
+				logger.FromContext(r.Context()).Warn("Admin authentication blocked",
+					"claimed", claimedIdentity(r), "reason", ReasonLockedOut)
+				w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds()+0.5)))
+				http.Error(w, "Too many failed login attempts", http.StatusTooManyRequests)
+				return
+			}
+
+			identity, claimed, reason := authenticate(r, tokens, opts.Users)
+			if identity == "" {
+				if reason != "" {
+					authFailures.WithLabelValues("invalid").Inc()
+					if failures.fail(client) {
+						reason = ReasonLockedOut
+					}
+					logger.FromContext(r.Context()).Warn("Admin authentication failed",
+						"claimed", claimed, "reason", reason)
+					if opts.Denied != nil {
+						opts.Denied(r, claimed, reason)
+					}
+				}
+				for _, c := range challenges {
+					w.Header().Add("WWW-Authenticate", c)
+				}
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			failures.reset(client)
+			ctx := audit.WithIdentity(r.Context(), identity)
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(logger.KeyActor, identity))
+			authed := r.WithContext(ctx)
+			next.ServeHTTP(w, authed)
+			// Hand the pattern a mux inside matched back to Metrics and
+			// Tracing, which read it from the request they passed in
+			r.Pattern = authed.Pattern
+		})
+	}
+}
+
+// bearerToken returns the token of a Bearer Authorization header, trimmed
+// of surrounding space, and whether the header used the Bearer scheme.
+func bearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token), ok
+}
+
+// claimedIdentity returns the username a request's basic auth credentials
+// claim, without checking them. Bearer tokens claim no identity.
+func claimedIdentity(r *http.Request) string {
+	username, _, _ := r.BasicAuth()
+	return username
+}
+
+// authenticate checks the request's credentials. It returns the identity on
+// success; otherwise the identity the request claimed, if any, and the reason
+// it was rejected, which is empty if it carried no credentials at all.
+func authenticate(r *http.Request, tokens map[[sha256.Size]byte]string, users map[string]string) (identity, claimed, reason string) {
+	if token, ok := bearerToken(r); ok {
+		sum := sha256.Sum256([]byte(token))
+		// Compare against every token so the time taken does not depend on
+		// which one matched
+		var name string
+		for candidate, holder := range tokens {
+			if subtle.ConstantTimeCompare(sum[:], candidate[:]) == 1 {
+				name = holder
+			}
+		}
+		if name == "" {
+			return "", "", ReasonInvalidToken
+		}
+		return "token:" + name, "", ""
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		hash, known := users[username]
+		if !known {
+			hash = string(dummyHash)
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil || !known {
+			return "", username, ReasonInvalidPassword
+		}
+		return username, "", ""
+	}
+	return "", "", ""
+}
+
+// failureLimiter counts failed attempts per client in fixed windows.
+type failureLimiter struct {
+	mu      sync.Mutex
+	max     int
+	window  time.Duration
+	clients map[string]*failureWindow
+}
+
+type failureWindow struct {
+	count int
+	start time.Time
+}
+
+// maxTrackedClients bounds the failure and rate limit tables. Once it is
+// reached, expired failure windows are swept and the least recently seen
+// rate limit bucket is dropped.
+const maxTrackedClients = 10000
+
+func newFailureLimiter(max int, window time.Duration) *failureLimiter {
+	return &failureLimiter{max: max, window: window, clients: make(map[string]*failureWindow)}
+}
+
+// blocked reports whether client has used up its attempts, and if so how
+// long until it may try again.
+func (l *failureLimiter) blocked(client string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.clients[client]
+	if !ok || f.count < l.max {
+		return 0, false
+	}
+	wait := time.Until(f.start.Add(l.window))
+	if wait <= 0 {
+		delete(l.clients, client)
+		return 0, false
+	}
+	return wait, true
+}
+
+// fail records a failed attempt and reports whether it used up the
+// client's attempts.
+func (l *failureLimiter) fail(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if len(l.clients) >= maxTrackedClients {
+		for c, f := range l.clients {
+			if now.Sub(f.start) >= l.window {
+				delete(l.clients, c)
+			}
+		}
+	}
+	f, ok := l.clients[client]
+	if !ok || now.Sub(f.start) >= l.window {
+		f = &failureWindow{start: now}
+		l.clients[client] = f
+	}
+	f.count++
+	return f.count == l.max
+}
+
+// reset forgets the client's failed attempts after it authenticates.
+func (l *failureLimiter) reset(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.clients, client)
+}
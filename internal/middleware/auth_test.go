@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthAuditsOnlyUntilLockout(t *testing.T) {
+	var denied []string
+	handler := Auth(AuthOptions{
+		Tokens:      map[string]string{"abcdefghijklmnopqrstu": "ops"},
+		MaxFailures: 3,
+		Lockout:     time.Minute,
+		Denied: func(r *http.Request, claimed, reason string) {
+			denied = append(denied, reason)
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	var codes []int
+	for i := 0; i < 6; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		r.Header.Set("Authorization", "Bearer wrong-token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		codes = append(codes, w.Code)
+	}
+
+	wantCodes := []int{401, 401, 401, 429, 429, 429}
+	for i := range wantCodes {
+		if codes[i] != wantCodes[i] {
+			t.Fatalf("status codes = %v, want %v", codes, wantCodes)
+		}
+	}
+	wantDenied := []string{ReasonInvalidToken, ReasonInvalidToken, ReasonLockedOut}
+	if len(denied) != len(wantDenied) {
+		t.Fatalf("Denied called with %q, want %q", denied, wantDenied)
+	}
+	for i := range wantDenied {
+		if denied[i] != wantDenied[i] {
+			t.Errorf("Denied call %d reason = %q, want %q", i, denied[i], wantDenied[i])
+		}
+	}
+}
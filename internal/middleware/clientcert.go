@@ -0,0 +1,31 @@
+// File: internal/middleware/clientcert.go
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/whatcher1074/stockspotlight/internal/audit"
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+)
+
+// ClientCert identifies requests that presented a verified TLS client
+// certificate as "cert:<common name>", stored the same way as by Auth. An
+// Auth inside it replaces the identity with the token or user that
+// authenticated. Requests without a verified certificate pass unchanged.
+func ClientCert() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			identity := "cert:" + r.TLS.VerifiedChains[0][0].Subject.CommonName
+			ctx := audit.WithIdentity(r.Context(), identity)
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(logger.KeyActor, identity))
+			identified := r.WithContext(ctx)
+			next.ServeHTTP(w, identified)
+			// See Auth
+			r.Pattern = identified.Pattern
+		})
+	}
+}
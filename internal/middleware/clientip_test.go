@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("127.0.0.1/32"),
+	}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		want       string
+	}{
+		{"direct client", "203.0.113.5:4321", nil, "203.0.113.5"},
+		{"untrusted peer's header ignored", "203.0.113.5:4321", []string{"198.51.100.1"}, "203.0.113.5"},
+		{"trusted proxy", "127.0.0.1:4321", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"spoofed hops left of the client", "127.0.0.1:4321", []string{"1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "127.0.0.1:4321", []string{"198.51.100.1, 10.0.0.2, 10.0.0.3"}, "198.51.100.1"},
+		{"repeated headers", "127.0.0.1:4321", []string{"1.1.1.1", "198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"all hops trusted", "127.0.0.1:4321", []string{"10.0.0.2"}, "10.0.0.2"},
+		{"garbage hop stops the walk", "127.0.0.1:4321", []string{"198.51.100.1, bogus, 10.0.0.2"}, "10.0.0.2"},
+		{"trusted proxy without header", "10.1.2.3:4321", nil, "10.1.2.3"},
+		{"ipv4-mapped hop", "127.0.0.1:4321", []string{"::ffff:198.51.100.1"}, "198.51.100.1"},
+		{"ipv6 client", "[2001:db8::1]:4321", nil, "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/data/news", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := ClientIP(r, trusted); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// File: internal/middleware/compress.go
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// brotliLevel trades some ratio for speed, since every response is
+// compressed on the fly.
+const brotliLevel = 4
+
+// minCompressSize is the smallest response, by Content-Length, worth
+// compressing. Responses without a Content-Length are always compressed.
+const minCompressSize = 1024
+
+// encoder is implemented by *gzip.Writer and *brotli.Writer.
+type encoder interface {
+	io.Writer
+	Flush() error
+	Close() error
+	Reset(io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	"br": {New: func() any { return brotli.NewWriterLevel(io.Discard, brotliLevel) }},
+	"gzip": {New: func() any {
+		zw, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return zw
+	}},
+}
+
+// Compress encodes text-like responses with brotli or gzip, whichever the
+// client accepts with the higher q-value, preferring brotli on a tie.
+// Responses that already have a Content-Encoding, partial content and
+// Server-Sent Events are passed through unchanged.
+func Compress() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks "br", "gzip" or "" from an Accept-Encoding header.
+func negotiateEncoding(header string) string {
+	var brQ, gzipQ float64
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "br":
+			brQ = q
+		case "gzip", "x-gzip":
+			gzipQ = q
+		}
+	}
+	switch {
+	case brQ > 0 && brQ >= gzipQ:
+		return "br"
+	case gzipQ > 0:
+		return "gzip"
+	}
+	return ""
+}
+
+// compressible reports whether a response of contentType is worth
+// compressing.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// compressWriter decides on the first WriteHeader or Write whether to
+// compress, based on the status and headers the handler has set.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	enc      encoder // nil until decided, and if not compressing
+	decided  bool
+}
+
+func (cw *compressWriter) decide(status int, body []byte) {
+	cw.decided = true
+	h := cw.Header()
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent || h.Get("Content-Encoding") != "" {
+		return
+	}
+	if h.Get("Content-Type") == "" && body != nil {
+		h.Set("Content-Type", http.DetectContentType(body))
+	}
+	if !compressible(h.Get("Content-Type")) {
+		return
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < minCompressSize {
+		return
+	}
+
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+	cw.enc = encoderPools[cw.encoding].Get().(encoder)
+	cw.enc.Reset(cw.ResponseWriter)
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if !cw.decided {
+		cw.decide(code, nil)
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.decided {
+		cw.decide(http.StatusOK, b)
+	}
+	if cw.enc == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+	return cw.enc.Write(b)
+}
+
+// Flush writes any buffered compressed data to the client.
+func (cw *compressWriter) Flush() {
+	if cw.enc != nil {
+		cw.enc.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// close finishes the compressed stream and returns the encoder to its pool.
+func (cw *compressWriter) close() {
+	if cw.enc == nil {
+		return
+	}
+	cw.enc.Close()
+	cw.enc.Reset(io.Discard)
+	encoderPools[cw.encoding].Put(cw.enc)
+	cw.enc = nil
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"x-gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip;q=0.5", "br"},
+		{"br;q=0.4, gzip;q=0.8", "gzip"},
+		{"gzip;q=0.8, br;q=0.9", "br"},
+		{"br;q=0, gzip", "gzip"},
+		{"br;q=0, gzip;q=0", ""},
+		{"GZIP ; q=1", "gzip"},
+		{"br;q=bogus", "br"},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	big := strings.Repeat("stock spotlight ", 200)
+	tests := []struct {
+		name        string
+		accept      string
+		method      string
+		rangeHeader string
+		contentType string
+		length      bool // set Content-Length
+		encoding    string
+		status      int
+		body        string
+		want        string
+	}{
+		{name: "brotli", accept: "gzip, br", contentType: "text/html", body: big, want: "br"},
+		{name: "gzip", accept: "gzip", contentType: "application/json", body: big, want: "gzip"},
+		{name: "sniffed type", accept: "gzip", body: "<html>" + big, want: "gzip"},
+		{name: "no accept-encoding", contentType: "text/html", body: big},
+		{name: "below minimum size", accept: "gzip", contentType: "text/html", length: true, body: "small"},
+		{name: "unknown length is compressed", accept: "gzip", contentType: "text/html", body: "small", want: "gzip"},
+		{name: "at minimum size", accept: "gzip", contentType: "text/html", length: true, body: big[:minCompressSize], want: "gzip"},
+		{name: "event stream", accept: "gzip", contentType: "text/event-stream", body: big},
+		{name: "binary", accept: "gzip", contentType: "image/png", body: big},
+		{name: "range request", accept: "gzip", rangeHeader: "bytes=0-10", contentType: "text/html", body: big},
+		{name: "head request", accept: "gzip", method: http.MethodHead, contentType: "text/html"},
+		{name: "already encoded", accept: "br", contentType: "text/html", encoding: "identity", body: big, want: "identity"},
+		{name: "no content", accept: "gzip", contentType: "text/html", status: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				if tt.length {
+					w.Header().Set("Content-Length", strconv.Itoa(len(tt.body)))
+				}
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				io.WriteString(w, tt.body)
+			}))
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Encoding", tt.accept)
+			}
+			if tt.rangeHeader != "" {
+				r.Header.Set("Range", tt.rangeHeader)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(w.Header().Get("Vary"), "Accept-Encoding") {
+				t.Errorf("Vary = %q, want Accept-Encoding", w.Header().Get("Vary"))
+			}
+			var body io.Reader = w.Body
+			switch tt.want {
+			case "gzip":
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = zr
+			case "br":
+				body = brotli.NewReader(w.Body)
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.body {
+				t.Errorf("decoded body = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestCompressFlushes(t *testing.T) {
+	tests := []struct {
+		name, contentType, want string
+	}{
+		{"event stream passes through", "text/event-stream", ""},
+		{"compressed stream", "text/plain", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler := Compress()(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.Header().Set("Content-Type", tt.contentType)
+				io.WriteString(rw, "data: first\n\n")
+				rw.(http.Flusher).Flush()
+				if !w.Flushed || w.Body.Len() == 0 {
+					t.Errorf("after Flush: flushed %v with %d bytes, want the event sent", w.Flushed, w.Body.Len())
+				}
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/logs/tail", nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+			handler.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.want {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && w.Body.String() != "data: first\n\n" {
+				t.Errorf("body = %q, want the event unchanged", w.Body.String())
+			}
+		})
+	}
+}
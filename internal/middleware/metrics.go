@@ -0,0 +1,72 @@
+// File: internal/middleware/metrics.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	authFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "http",
+		Name:      "auth_failures_total",
+		Help:      "Rejected admin requests: invalid credentials, or blocked after too many failures.",
+	}, []string{"reason"})
+
+	panics = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "http",
+		Name:      "panics_total",
+		Help:      "Handler panics caught by the recovery middleware.",
+	})
+
+	rateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "stockspotlight",
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected by the per-client rate limit.",
+	})
+)
+
+// Metrics counts requests and observes their latency by route. The route is
+// the ServeMux pattern that matched, which the mux records on the request it
+// is given, so Metrics must be the innermost middleware that replaces the
+// request; only Recover, which does not, may sit between it and the mux.
+func Metrics() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := newResponseRecorder(w)
+
+			next.ServeHTTP(rec, r)
+
+			route := r.Pattern
+			if route == "" {
+				route = "unmatched"
+			}
+			labels := []string{route, r.Method, strconv.Itoa(rec.Status())}
+			httpRequests.WithLabelValues(labels...).Inc()
+			httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		})
+	}
+}
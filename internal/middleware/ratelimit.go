@@ -0,0 +1,138 @@
+// File: internal/middleware/ratelimit.go
+package middleware
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"crypto/subtle"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+)
+
+// RateLimits is the rate each client may sustain and the burst it may make
+// at once. A zero PerMinute disables limiting.
+type RateLimits struct {
+	PerMinute int
+	Burst     int
+}
+
+// RateLimitOptions configures RateLimit.
+type RateLimitOptions struct {
+	// Limits is called on every request, so changed limits apply at once.
+	Limits func() RateLimits
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header
+	// names the client.
+	TrustedProxies []netip.Prefix
+	// Tokens maps bearer tokens to the names of their holders. A request
+	// carrying one is limited per token rather than per address.
+	Tokens map[string]string
+	// OnLimited writes the response to a rejected request, after Retry-After
+	// has been set.
+	OnLimited http.Handler
+}
+
+// RateLimit limits each client to opts.Limits using a token bucket per
+// client, answering requests over the limit with 429 and Retry-After.
+func RateLimit(opts RateLimitOptions) Middleware {
+	tokens := make(map[[sha256.Size]byte]string, len(opts.Tokens))
+	for token, name := range opts.Tokens {
+		tokens[sha256.Sum256([]byte(token))] = name
+	}
+	buckets := newBucketTable(maxTrackedClients)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limits := opts.Limits()
+			if limits.PerMinute <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client := rateLimitKey(r, tokens, opts.TrustedProxies)
+			wait, ok := buckets.take(client, limits, time.Now())
+			if ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rateLimited.Inc()
+			logger.FromContext(r.Context()).Info("Client rate limited", "client", client, "retry_after", wait.Round(time.Millisecond).String())
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			opts.OnLimited.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey identifies the client a request is counted against: the name
+// of a known bearer token, or else the client's IP address.
+func rateLimitKey(r *http.Request, tokens map[[sha256.Size]byte]string, trusted []netip.Prefix) string {
+	if bearer, ok := bearerToken(r); ok && bearer != "" {
+		sum := sha256.Sum256([]byte(bearer))
+		var name string
+		for known, n := range tokens {
+			if subtle.ConstantTimeCompare(sum[:], known[:]) == 1 {
+				name = n
+			}
+		}
+		if name != "" {
+			return "token:" + name
+		}
+	}
+	return "ip:" + ClientIP(r, trusted)
+}
+
+// bucketTable holds a token bucket per client, forgetting the least
+// recently seen client once it holds max.
+type bucketTable struct {
+	mu      sync.Mutex
+	max     int
+	buckets map[string]*list.Element // of *bucket
+	recent  *list.List               // most recently seen first
+}
+
+type bucket struct {
+	client string
+	tokens float64
+	last   time.Time
+}
+
+func newBucketTable(max int) *bucketTable {
+	return &bucketTable{max: max, buckets: make(map[string]*list.Element), recent: list.New()}
+}
+
+// take spends one of client's tokens if it has one. Otherwise it reports
+// how long until it will.
+func (t *bucketTable) take(client string, limits RateLimits, now time.Time) (time.Duration, bool) {
+	perSecond := float64(limits.PerMinute) / 60
+	burst := float64(limits.Burst)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var b *bucket
+	if e, ok := t.buckets[client]; ok {
+		t.recent.MoveToFront(e)
+		b = e.Value.(*bucket)
+	} else {
+		if t.recent.Len() >= t.max {
+			oldest := t.recent.Back()
+			t.recent.Remove(oldest)
+			delete(t.buckets, oldest.Value.(*bucket).client)
+		}
+		b = &bucket{client: client, tokens: burst, last: now}
+		t.buckets[client] = t.recent.PushFront(b)
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / perSecond * float64(time.Second)), false
+}
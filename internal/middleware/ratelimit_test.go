@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBucketTableTake(t *testing.T) {
+	limits := RateLimits{PerMinute: 60, Burst: 3} // one token a second
+	start := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		at       time.Duration // since start
+		wantOK   bool
+		wantWait time.Duration
+	}{
+		{"burst 1", 0, true, 0},
+		{"burst 2", 0, true, 0},
+		{"burst 3", 0, true, 0},
+		{"burst used up", 0, false, time.Second},
+		{"partly refilled", 500 * time.Millisecond, false, 500 * time.Millisecond},
+		{"refilled one token", time.Second, true, 0},
+		{"spent again", time.Second, false, time.Second},
+		{"refill capped at burst", time.Hour, true, 0},
+		{"capped burst 2", time.Hour, true, 0},
+		{"capped burst 3", time.Hour, true, 0},
+		{"capped burst used up", time.Hour, false, time.Second},
+	}
+	table := newBucketTable(10)
+	for _, tt := range tests {
+		wait, ok := table.take("ip:203.0.113.5", limits, start.Add(tt.at))
+		if ok != tt.wantOK || (wait-tt.wantWait).Abs() > time.Millisecond {
+			t.Errorf("%s: take() = %v, %v; want %v, %v", tt.name, wait, ok, tt.wantWait, tt.wantOK)
+		}
+	}
+}
+
+func TestBucketTableEvictsLeastRecentlyUsed(t *testing.T) {
+	limits := RateLimits{PerMinute: 1, Burst: 1}
+	now := time.Unix(1700000000, 0)
+	table := newBucketTable(2)
+
+	table.take("a", limits, now)
+	table.take("b", limits, now)
+	table.take("a", limits, now) // a is now the most recent, and limited
+	table.take("c", limits, now) // evicts b
+
+	if _, ok := table.buckets["b"]; ok {
+		t.Error("least recently used bucket b was kept")
+	}
+	if _, ok := table.take("a", limits, now); ok {
+		t.Error("recently used bucket a was evicted and its limit reset")
+	}
+	if n := len(table.buckets); n != 2 {
+		t.Errorf("table holds %d buckets, want 2", n)
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	tokens := map[[sha256.Size]byte]string{sha256.Sum256([]byte("abcdefghijklmnopqrstu")): "ci"}
+	tests := []struct {
+		name, authorization, want string
+	}{
+		{"no credentials", "", "ip:203.0.113.5"},
+		{"known token", "Bearer abcdefghijklmnopqrstu", "token:ci"},
+		{"known token with extra space", "Bearer  abcdefghijklmnopqrstu ", "token:ci"},
+		{"unknown token", "Bearer nope", "ip:203.0.113.5"},
+		{"empty token", "Bearer ", "ip:203.0.113.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/data/news", nil)
+			r.RemoteAddr = "203.0.113.5:4321"
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			if got := rateLimitKey(r, tokens, nil); got != tt.want {
+				t.Errorf("rateLimitKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// File: internal/middleware/recover.go
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+)
+
+// Recover turns a panic in a handler into an Error record with the stack
+// and, if the handler had not started its response, the response written by
+// onPanic. http.ErrAbortHandler is re-panicked, as net/http expects. Recover
+// should run directly around the mux, inside Metrics, so that every other
+// middleware sees the response onPanic writes.
+func Recover(onPanic http.Handler) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := newResponseRecorder(w)
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				panics.Inc()
+				logger.FromContext(r.Context()).Error("Handler panicked",
+					"panic", fmt.Sprint(v),
+					"stack", string(debug.Stack()),
+				)
+				if rec.status == 0 {
+					onPanic.ServeHTTP(w, r)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
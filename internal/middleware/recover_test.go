@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   string
+		onPanic    int // calls to onPanic
+	}{
+		{
+			name:       "no panic",
+			handler:    func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "ok") },
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			name:       "panic before the response",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "internal error",
+			onPanic:    1,
+		},
+		{
+			name: "panic after the headers were sent",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				io.WriteString(w, "partial")
+				panic("boom")
+			},
+			wantStatus: http.StatusAccepted,
+			wantBody:   "partial",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			onPanic := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				http.Error(w, "internal error", http.StatusInternalServerError)
+			})
+			w := httptest.NewRecorder()
+			Recover(onPanic)(tt.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody && got != tt.wantBody+"\n" {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if calls != tt.onPanic {
+				t.Errorf("onPanic called %d times, want %d", calls, tt.onPanic)
+			}
+		})
+	}
+}
+
+func TestRecoverRepanicsAbortHandler(t *testing.T) {
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	handler := Recover(http.NotFoundHandler())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	t.Error("ServeHTTP returned, want it to panic")
+}
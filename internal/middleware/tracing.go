@@ -0,0 +1,58 @@
+// File: internal/middleware/tracing.go
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+	"github.com/whatcher1074/stockspotlight/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("internal/middleware")
+
+// Tracing starts a server span for each request, continuing a trace from an
+// incoming traceparent header, and adds trace_id and span_id to the
+// request-scoped logger. It should run inside RequestID, so the logger it
+// extends carries the request ID, and outside AccessLog, so the access log
+// carries the trace ID. The span is named after the ServeMux pattern that
+// matched, which needs Metrics, or nothing at all, between it and the mux.
+func Tracing() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("request_id", RequestIDFrom(ctx)),
+				))
+			defer span.End()
+
+			if sc := span.SpanContext(); sc.IsValid() {
+				ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(
+					logger.KeyTraceID, sc.TraceID().String(),
+					logger.KeySpanID, sc.SpanID().String(),
+				))
+			}
+
+			rec := newResponseRecorder(w)
+			r = r.WithContext(ctx)
+			next.ServeHTTP(rec, r)
+
+			if r.Pattern != "" {
+				span.SetName(r.Method + " " + r.Pattern)
+				span.SetAttributes(attribute.String("http.route", r.Pattern))
+			}
+			status := rec.Status()
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
@@ -0,0 +1,468 @@
+// File: internal/server/admin.go
+package server
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/whatcher1074/stockspotlight/internal/api"
+	"github.com/whatcher1074/stockspotlight/internal/audit"
+	"github.com/whatcher1074/stockspotlight/internal/config"
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+	"github.com/whatcher1074/stockspotlight/internal/middleware"
+)
+
+// registerAdmin adds the metrics, log, config, audit, cache and quota
+// endpoints to mux.
+func (s *Server) registerAdmin(mux *http.ServeMux) {
+	// Prometheus metrics, registered by each internal package, plus Go runtime stats
+	mux.Handle("/metrics", promhttp.Handler())
+
+	// Log management endpoints
+	mux.HandleFunc("/logs/status", s.handleLogStatus)
+	mux.HandleFunc("/logs/tail", s.handleLogTail)
+	mux.HandleFunc("/logs/search", s.handleLogSearch)
+	mux.HandleFunc("/logs/sinks", s.handleLogSinks)
+	mux.HandleFunc("/logs/rotate", s.handleLogRotate)
+	mux.HandleFunc("/logs/cleanup", s.handleLogCleanup)
+
+	mux.HandleFunc("/admin/config", s.handleConfig)
+	mux.HandleFunc("/admin/audit", s.handleAudit)
+	mux.HandleFunc("/admin/log-level", s.handleLogLevel)
+	mux.HandleFunc("/admin/cache", s.handleCache)
+	mux.HandleFunc("/admin/quota", s.handleQuota)
+}
+
+// registerDebugHandlers adds the net/http/pprof profiles and expvar's
+// /debug/vars to mux.
+func registerDebugHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+}
+
+// adminAuth builds the authentication middleware for the admin endpoints.
+// Failed attempts, up to the one that blocks the client, are recorded in the
+// audit log as denied.
+func (s *Server) adminAuth(cfg config.AdminAuthConfig, trusted []netip.Prefix) middleware.Middleware {
+	opts := middleware.AuthOptions{
+		Tokens:         make(map[string]string, len(cfg.Tokens)),
+		Users:          make(map[string]string, len(cfg.Users)),
+		MaxFailures:    cfg.MaxFailures,
+		Lockout:        cfg.Lockout.Duration,
+		TrustedProxies: trusted,
+	}
+	for _, t := range cfg.Tokens {
+		opts.Tokens[t.Token.Value()] = t.Name
+	}
+	for _, u := range cfg.Users {
+		opts.Users[u.Username] = u.PasswordHash.Value()
+	}
+	opts.Denied = func(r *http.Request, claimed, reason string) {
+		actor := claimed
+		if actor == "" {
+			actor = audit.Anonymous
+		}
+		err := s.audit.Record(audit.Event{
+			Actor:      actor,
+			RemoteAddr: middleware.ClientIP(r, trusted),
+			RequestID:  middleware.RequestIDFrom(r.Context()),
+			Action:     "admin.auth",
+			Outcome:    audit.OutcomeDenied,
+			Error:      reason,
+			Details:    map[string]any{"method": r.Method, "path": r.URL.Path},
+		})
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to write audit event", "action", "admin.auth", logger.Err(err))
+		}
+	}
+	return middleware.Auth(opts)
+}
+
+// recordAudit writes the audit event for an admin request, with the client
+// address as told by middleware.ClientIP. A nil err is recorded as success.
+func (s *Server) recordAudit(r *http.Request, action string, err error, details map[string]any) {
+	ev := audit.Event{
+		Actor:      audit.IdentityFrom(r.Context()),
+		RemoteAddr: middleware.ClientIP(r, s.trusted),
+		RequestID:  middleware.RequestIDFrom(r.Context()),
+		Action:     action,
+		Outcome:    audit.OutcomeSuccess,
+		Details:    details,
+	}
+	if err != nil {
+		ev.Outcome, ev.Error = audit.OutcomeFailure, err.Error()
+	}
+	if err := s.audit.Record(ev); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to write audit event", "action", action, logger.Err(err))
+	}
+}
+
+// handleLogStatus reports log file statistics, rotation limits and
+// suppressed record counts.
+func (s *Server) handleLogStatus(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	stats, err := s.logger.GetStats()
+	if err != nil {
+		reqLog.Error("Error getting log stats", logger.Err(err))
+		http.Error(w, fmt.Sprintf("Error getting log stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	suppressed, _ := json.Marshal(s.logger.SuppressionStats())
+	limits := s.logger.Limits()
+	maxTotal := "unlimited"
+	if limits.MaxTotalSize > 0 {
+		maxTotal = stats.FormatSize(limits.MaxTotalSize)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := fmt.Sprintf(`{
+		"currentSize": "%s",
+		"currentAge": "%v",
+		"rotatedFiles": %d,
+		"compressedFiles": %d,
+		"totalSize": "%s",
+		"maxSize": "%s",
+		"maxAge": "%v",
+		"maxFiles": %d,
+		"maxTotalSize": %q,
+		"suppressed": %s,
+		"status": "healthy"
+	}`,
+		stats.FormatSize(stats.CurrentSize),
+		stats.CurrentAge.Round(time.Minute),
+		stats.RotatedCount,
+		stats.CompressedCount,
+		stats.FormatSize(stats.TotalSize),
+		stats.FormatSize(limits.MaxSize),
+		limits.MaxAge,
+		limits.MaxFiles,
+		maxTotal,
+		suppressed,
+	)
+	w.Write([]byte(response))
+	reqLog.Debug("Log status requested", "stats", stats.String())
+}
+
+// handleLogTail streams new log lines over Server-Sent Events until the
+// client goes away or the server shuts down:
+// ?level=warn&q=substring&request_id=ID
+func (s *Server) handleLogTail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	filter, err := logFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The stream outlives server.write_timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		logger.FromContext(r.Context()).Warn("Could not lift write deadline for log tail", logger.Err(err))
+	}
+
+	sub := s.logger.Subscribe()
+	defer sub.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": tailing logs\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.stopping:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case line := <-sub.C:
+			rec := logger.ParseRecord(string(line))
+			if !filter.Match(rec) {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", rec.Line)
+			flusher.Flush()
+		}
+	}
+}
+
+// handleLogSearch searches current and rotated (including compressed) logs:
+// ?from=RFC3339&to=RFC3339&pattern=regexp&level=&request_id=&q=&offset=&limit=
+func (s *Server) handleLogSearch(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	filter, err := logFilterFromQuery(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	result, err := s.logger.Search(filter, offset, limit)
+	if err != nil {
+		reqLog.Error("Error searching logs", logger.Err(err))
+		http.Error(w, fmt.Sprintf("Error searching logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		reqLog.Error("Error encoding log search results", logger.Err(err))
+	}
+}
+
+// handleLogSinks reports the delivery counters of the remote log sinks.
+func (s *Server) handleLogSinks(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.logger.SinkStats()); err != nil {
+		reqLog.Error("Error encoding log sink stats", logger.Err(err))
+	}
+}
+
+// handleLogRotate forces a log rotation.
+func (s *Server) handleLogRotate(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reqLog.Info("Manual log rotation requested via API")
+	err := s.logger.ForceRotate()
+	s.recordAudit(r, "logs.rotate", err, nil)
+	if err != nil {
+		reqLog.Error("Error rotating logs", logger.Err(err))
+		http.Error(w, fmt.Sprintf("Error rotating logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status": "success", "message": "Log rotation completed"}`))
+	reqLog.Info("Manual log rotation completed successfully")
+}
+
+// handleLogCleanup deletes rotated logs beyond the retention limits.
+func (s *Server) handleLogCleanup(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reqLog.Info("Manual log cleanup requested via API")
+	err := s.logger.CleanupOldLogs()
+	s.recordAudit(r, "logs.cleanup", err, nil)
+	if err != nil {
+		reqLog.Error("Error cleaning up logs", logger.Err(err))
+		http.Error(w, fmt.Sprintf("Error cleaning up logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status": "success", "message": "Log cleanup completed"}`))
+	reqLog.Info("Manual log cleanup completed successfully")
+}
+
+// handleConfig shows the effective config, secrets redacted.
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.config.Current().View()); err != nil {
+		reqLog.Error("Error encoding config view", logger.Err(err))
+	}
+}
+
+// handleAudit queries the audit trail of admin actions:
+// ?action=logs.&actor=&outcome=&from=&to=&offset=&limit=
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	filter := audit.Filter{
+		Action:  query.Get("action"),
+		Actor:   query.Get("actor"),
+		Outcome: query.Get("outcome"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		if v := query.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s time, want RFC3339: %v", p.name, err), http.StatusBadRequest)
+				return
+			}
+			*p.dst = t
+		}
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	result, err := s.audit.Query(filter, offset, limit)
+	if err != nil {
+		reqLog.Error("Error querying audit log", logger.Err(err))
+		http.Error(w, fmt.Sprintf("Error querying audit log: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		reqLog.Error("Error encoding audit events", logger.Err(err))
+	}
+}
+
+// handleLogLevel reads the minimum log level on GET and changes it on
+// POST ?level=debug|info|warn|error.
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		level, err := logger.ParseLevel(r.FormValue("level"))
+		if err != nil {
+			s.recordAudit(r, "logging.set_level", err, map[string]any{"level": r.FormValue("level")})
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		old := s.logger.Level()
+		s.logger.SetLevel(level)
+		reqLog.Warn("Log level changed via API", "old", old.String(), "new", level.String())
+		s.recordAudit(r, "logging.set_level", nil, map[string]any{"old": old.String(), "new": level.String()})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"level": %q}`, s.logger.Level())
+}
+
+// handleCache lists cached keys on GET and clears them on POST or DELETE,
+// all of them or those starting with ?prefix=.
+func (s *Server) handleCache(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.cache.Entries()); err != nil {
+			reqLog.Error("Error encoding cache entries", logger.Err(err))
+		}
+	case http.MethodPost, http.MethodDelete:
+		prefix := r.FormValue("prefix")
+		removed := s.cache.Clear(prefix)
+		reqLog.Info("Cache cleared via API", "prefix", prefix, "removed", removed)
+		s.recordAudit(r, "cache.clear", nil, map[string]any{"prefix": prefix, "removed": removed})
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status": "success", "removed": %d}`, removed)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleQuota reports the upstream quota as last reported by the provider,
+// our own request limit and the recent upstream error rate.
+func (s *Server) handleQuota(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cfg := s.config.Current()
+	rate, calls := api.ErrorRate(upstreamErrorWindow)
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(map[string]any{
+		"provider":          cfg.Provider.Name,
+		"requestsPerMinute": cfg.RateLimit.RequestsPerMinute,
+		"quota":             api.CurrentQuota(),
+		"recentCalls":       calls,
+		"recentErrorRate":   rate,
+	})
+	if err != nil {
+		reqLog.Error("Error encoding quota", logger.Err(err))
+	}
+}
+
+// logFilterFromQuery builds a log filter from the level, q, pattern,
+// request_id, from and to query parameters.
+func logFilterFromQuery(q url.Values) (logger.Filter, error) {
+	filter := logger.Filter{
+		Contains:  q.Get("q"),
+		RequestID: q.Get("request_id"),
+	}
+	if v := q.Get("level"); v != "" {
+		level, err := logger.ParseLevel(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.MinLevel = &level
+	}
+	if v := q.Get("pattern"); v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid pattern: %v", err)
+		}
+		filter.Pattern = re
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s time, want RFC3339: %v", p.name, err)
+			}
+			*p.dst = t
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,44 @@
+// File: internal/server/errors.go
+package server
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/whatcher1074/stockspotlight/internal/middleware"
+)
+
+// contentSecurityPolicy allows the CDNs the templates load htmx, Bootstrap
+// and fonts from. Inline scripts and styles are still used by the templates.
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' https://unpkg.com https://cdn.jsdelivr.net; " +
+	"style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net https://fonts.googleapis.com; " +
+	"font-src 'self' https://fonts.gstatic.com https://cdn.jsdelivr.net; " +
+	"img-src 'self' data: https:; " +
+	"connect-src 'self'; " +
+	"frame-ancestors 'none'; base-uri 'self'; form-action 'self'"
+
+// renderError writes msg with status. HTMX requests get an alert fragment
+// marked with X-Error-Fragment, which the dashboard swaps in despite the
+// error status; everything else gets plain text.
+func renderError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, msg, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Error-Fragment", "true")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<div class="alert alert-danger" role="alert"><i class="me-2">⚠️</i>%s</div>`, html.EscapeString(msg))
+}
+
+// handlePanic answers a request whose handler panicked, after Recover has
+// logged it. The request ID lets users point at the log entry.
+func (s *Server) handlePanic(w http.ResponseWriter, r *http.Request) {
+	msg := "Something went wrong"
+	if id := middleware.RequestIDFrom(r.Context()); id != "" {
+		msg += " (request " + id + ")"
+	}
+	renderError(w, r, http.StatusInternalServerError, msg)
+}
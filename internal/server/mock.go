@@ -0,0 +1,121 @@
+// File: internal/server/mock.go
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// mockProfile returns placeholder company profile data for symbol until the
+// profile widget is backed by the provider.
+func mockProfile(symbol string) map[string]interface{} {
+	profiles := map[string]map[string]interface{}{
+		"AAPL": {
+			"Name":     "Apple Inc.",
+			"Industry": "Technology Hardware, Storage & Peripherals",
+			"WebURL":   "https://www.apple.com",
+			"Logo":     "https://logo.clearbit.com/apple.com",
+		},
+		"MSFT": {
+			"Name":     "Microsoft Corporation",
+			"Industry": "Systems Software",
+			"WebURL":   "https://www.microsoft.com",
+			"Logo":     "https://logo.clearbit.com/microsoft.com",
+		},
+		"GOOGL": {
+			"Name":     "Alphabet Inc.",
+			"Industry": "Interactive Media & Services",
+			"WebURL":   "https://www.google.com",
+			"Logo":     "https://logo.clearbit.com/google.com",
+		},
+		"TSLA": {
+			"Name":     "Tesla, Inc.",
+			"Industry": "Automobiles",
+			"WebURL":   "https://www.tesla.com",
+			"Logo":     "https://logo.clearbit.com/tesla.com",
+		},
+	}
+
+	if profile, exists := profiles[symbol]; exists {
+		return profile
+	}
+	return map[string]interface{}{
+		"Name":     fmt.Sprintf("%s Corporation", symbol),
+		"Industry": "Technology",
+		"WebURL":   fmt.Sprintf("https://www.%s.com", symbol),
+		"Logo":     fmt.Sprintf("https://logo.clearbit.com/%s.com", symbol),
+	}
+}
+
+// mockNews returns placeholder articles for category, timed relative to now,
+// falling back to general news for unknown categories.
+func mockNews(category string, now time.Time) []map[string]interface{} {
+	const layout = "Jan 2, 2006 15:04 MST"
+	newsData := map[string][]map[string]interface{}{
+		"general": {
+			{
+				"Headline": "Stock Market Reaches New Highs Amid Economic Optimism",
+				"URL":      "https://example.com/news1",
+				"Source":   "Financial Times",
+				"Time":     now.Format(layout),
+			},
+			{
+				"Headline": "Federal Reserve Maintains Interest Rates",
+				"URL":      "https://example.com/news3",
+				"Source":   "Bloomberg",
+				"Time":     now.Add(-2 * time.Hour).Format(layout),
+			},
+			{
+				"Headline": "Global Markets Show Strong Recovery Signs",
+				"URL":      "https://example.com/news4",
+				"Source":   "Reuters",
+				"Time":     now.Add(-3 * time.Hour).Format(layout),
+			},
+		},
+		"tech": {
+			{
+				"Headline": "Tech Giants Report Strong Quarterly Earnings",
+				"URL":      "https://example.com/tech1",
+				"Source":   "TechCrunch",
+				"Time":     now.Add(-1 * time.Hour).Format(layout),
+			},
+			{
+				"Headline": "AI Innovation Drives Tech Sector Growth",
+				"URL":      "https://example.com/tech2",
+				"Source":   "Wired",
+				"Time":     now.Add(-2 * time.Hour).Format(layout),
+			},
+			{
+				"Headline": "Cloud Computing Revenue Surges 40%",
+				"URL":      "https://example.com/tech3",
+				"Source":   "Ars Technica",
+				"Time":     now.Add(-4 * time.Hour).Format(layout),
+			},
+		},
+		"finance": {
+			{
+				"Headline": "Banking Sector Shows Resilience in Q4",
+				"URL":      "https://example.com/fin1",
+				"Source":   "Wall Street Journal",
+				"Time":     now.Add(-30 * time.Minute).Format(layout),
+			},
+			{
+				"Headline": "Cryptocurrency Market Volatility Continues",
+				"URL":      "https://example.com/fin2",
+				"Source":   "CoinDesk",
+				"Time":     now.Add(-1 * time.Hour).Format(layout),
+			},
+			{
+				"Headline": "Corporate Bond Yields Rise Amid Inflation Concerns",
+				"URL":      "https://example.com/fin3",
+				"Source":   "Financial Times",
+				"Time":     now.Add(-3 * time.Hour).Format(layout),
+			},
+		},
+	}
+
+	if articles, exists := newsData[category]; exists {
+		return articles
+	}
+	return newsData["general"] // Fallback to general
+}
@@ -0,0 +1,60 @@
+// File: internal/server/readiness.go
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/api"
+	"github.com/whatcher1074/stockspotlight/internal/config"
+	"github.com/whatcher1074/stockspotlight/internal/health"
+)
+
+// upstreamErrorWindow is the period the upstream error rate check covers.
+// Only real provider requests count; while the widgets use mock data that
+// means the provider check's own pings.
+const upstreamErrorWindow = 5 * time.Minute
+
+// minUpstreamCalls is how many recent calls the error rate check needs
+// before it judges the provider.
+const minUpstreamCalls = 5
+
+// readiness registers the checks behind /healthz/ready.
+func (s *Server) readiness(cfg *config.Config) *health.Registry {
+	checks := health.NewRegistry(cfg.Health.Timeout.Duration)
+
+	checks.Register("provider", health.Cached(s.provider.Ping, cfg.Health.ProviderInterval.Duration))
+
+	maxErrorRate := cfg.Health.MaxErrorRate
+	checks.Register("upstream_error_rate", func(ctx context.Context) error {
+		rate, calls := api.ErrorRate(upstreamErrorWindow)
+		if calls >= minUpstreamCalls && rate > maxErrorRate {
+			return fmt.Errorf("%.0f%% of %d upstream calls failed in the last %v", rate*100, calls, upstreamErrorWindow)
+		}
+		return nil
+	})
+
+	// Len takes the cache lock without touching entries or the hit and miss
+	// metrics, so the check only fails, by timing out, if the cache is stuck
+	checks.Register("cache", func(ctx context.Context) error {
+		s.cache.Len()
+		return nil
+	})
+
+	// CheckWritable creates a file in the log directory, which /healthz/ready
+	// being public should not let clients do at will
+	checks.Register("log_file", health.Cached(func(ctx context.Context) error {
+		return s.logger.CheckWritable()
+	}, cfg.Health.ProviderInterval.Duration))
+
+	checks.Register("quota", func(ctx context.Context) error {
+		q := api.CurrentQuota()
+		if q.Known && q.Remaining <= 0 && time.Now().Before(q.Reset) {
+			return fmt.Errorf("provider quota exhausted until %s", q.Reset.Format(time.RFC3339))
+		}
+		return nil
+	})
+
+	return checks
+}
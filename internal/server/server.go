@@ -0,0 +1,312 @@
+// File: internal/server/server.go
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/api"
+	"github.com/whatcher1074/stockspotlight/internal/audit"
+	"github.com/whatcher1074/stockspotlight/internal/cache"
+	"github.com/whatcher1074/stockspotlight/internal/config"
+	"github.com/whatcher1074/stockspotlight/internal/health"
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+	"github.com/whatcher1074/stockspotlight/internal/middleware"
+)
+
+// Provider is the upstream market data source behind the screener widgets.
+// api.Finnhub implements it.
+type Provider interface {
+	FetchAllData(ctx context.Context, tickers []string, limit int, signal string) ([]api.CombinedData, error)
+	Ping(ctx context.Context) error
+}
+
+// Options holds the dependencies of a Server.
+type Options struct {
+	// Config is read on every request, so reloaded settings apply at once.
+	// Listener addresses, admin credentials and health settings are read
+	// once, by New and Run.
+	Config    *config.Store
+	Provider  Provider
+	Cache     *cache.Cache
+	Logger    *logger.Logger
+	Audit     *audit.Log
+	Templates *Templates
+	// StaticDir is served under /static/.
+	StaticDir string
+	// Started is reported as the start of uptime on /healthz.
+	Started time.Time
+}
+
+// Server serves the dashboard, its data fragments, health checks and the
+// admin endpoints.
+type Server struct {
+	config    *config.Store
+	provider  Provider
+	cache     *cache.Cache
+	logger    *logger.Logger
+	audit     *audit.Log
+	templates *Templates
+	staticDir string
+	started   time.Time
+
+	// fetches records the last fresh data per widget, for /healthz
+	fetches *health.Fetches
+	// trusted are the parsed server.trusted_proxies, read once at startup
+	trusted []netip.Prefix
+
+	public http.Handler
+	admin  http.Handler // nil unless admin.address is set
+
+	// stopping is closed when shutdown starts, ending the log tail streams,
+	// which Shutdown would otherwise wait for
+	stopping    chan struct{}
+	stopStreams func()
+}
+
+// New creates a Server and builds its routes.
+func New(opts Options) *Server {
+	s := &Server{
+		config:    opts.Config,
+		provider:  opts.Provider,
+		cache:     opts.Cache,
+		logger:    opts.Logger,
+		audit:     opts.Audit,
+		templates: opts.Templates,
+		staticDir: opts.StaticDir,
+		started:   opts.Started,
+		fetches:   health.NewFetches(),
+		stopping:  make(chan struct{}),
+	}
+	s.stopStreams = sync.OnceFunc(func() { close(s.stopping) })
+	s.buildRoutes()
+	return s
+}
+
+// Routes returns the handler for the public port, with middleware applied.
+func (s *Server) Routes() http.Handler {
+	return s.public
+}
+
+// AdminRoutes returns the handler for the admin listener, with middleware
+// applied, or nil if admin.address is not set and the admin endpoints are
+// part of Routes.
+func (s *Server) AdminRoutes() http.Handler {
+	return s.admin
+}
+
+// buildRoutes registers every endpoint. The admin endpoints get a listener of
+// their own at admin.address, which also serves pprof and expvar and may
+// require client certificates, or else share the public port. Either way
+// they sit behind admin.auth, which Validate requires on the public port.
+func (s *Server) buildRoutes() {
+	cfg := s.config.Current()
+
+	s.trusted, _ = cfg.Server.ParseTrustedProxies() // checked by Validate
+
+	mux := http.NewServeMux()
+	s.registerPublic(mux, cfg, s.trusted)
+
+	adminMux := http.NewServeMux()
+	s.registerAdmin(adminMux)
+	adminRoutes := http.Handler(adminMux)
+	if cfg.Admin.Auth.Enabled() {
+		adminRoutes = s.adminAuth(cfg.Admin.Auth, s.trusted)(adminMux)
+	}
+
+	switch {
+	case cfg.Admin.Address != "":
+		if !cfg.Admin.Auth.Enabled() && cfg.Admin.ClientCAFile == "" {
+			s.logger.Warn("No admin.auth credentials configured, admin endpoints are unauthenticated",
+				"address", cfg.Admin.Address)
+		}
+		registerDebugHandlers(adminMux)
+		s.admin = s.withMiddleware(middleware.ClientCert()(adminRoutes))
+	case cfg.Admin.Auth.Enabled():
+		for _, prefix := range []string{"/metrics", "/logs/", "/admin/"} {
+			mux.Handle(prefix, adminRoutes)
+		}
+	default:
+		// Never serve them open on the public port, even if the config
+		// skipped Validate
+		s.logger.Error("No admin.auth credentials configured, admin endpoints are not served")
+	}
+	s.public = s.withMiddleware(mux)
+}
+
+// withMiddleware wraps h in the middleware every listener shares.
+func (s *Server) withMiddleware(h http.Handler) http.Handler {
+	return middleware.Chain(h,
+		middleware.RequestID(s.logger.Slog()),
+		middleware.Tracing(),
+		middleware.AccessLog(),
+		middleware.SecurityHeaders(contentSecurityPolicy),
+		middleware.Compress(),
+		middleware.Metrics(), // reads the pattern the mux matched
+		middleware.Recover(http.HandlerFunc(s.handlePanic)),
+	)
+}
+
+// Run serves the public port, and the admin listener and HTTPS redirect if
+// configured, until ctx is cancelled, then shuts them down gracefully within
+// server.shutdown_timeout. It returns early if a listener fails.
+func (s *Server) Run(ctx context.Context) error {
+	cfg := s.config.Current()
+	port := strconv.Itoa(cfg.Server.Port)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	certs, err := s.loadCertificates(ctx, cfg.TLS)
+	if err != nil {
+		return err
+	}
+
+	var listeners []listener
+	defer func() {
+		for _, l := range listeners {
+			l.ln.Close()
+		}
+	}()
+
+	ln, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", port, err)
+	}
+	public := s.httpServer(s.public, cfg.Server)
+	if certs != nil {
+		public.TLSConfig = tlsConfig(certs)
+	}
+	listeners = append(listeners, listener{public, ln})
+
+	if s.admin != nil {
+		adminLn, err := listenAdmin(cfg.Admin)
+		if err != nil {
+			return fmt.Errorf("could not listen on admin address %s: %w", cfg.Admin.Address, err)
+		}
+		adminServer := s.httpServer(s.admin, cfg.Server)
+		// Profiles and traces run for as long as asked to
+		adminServer.WriteTimeout = 0
+		// A Unix socket is protected by its permissions instead
+		if network, _ := cfg.Admin.Listen(); certs != nil && network == "tcp" {
+			adminServer.TLSConfig, err = adminTLSConfig(certs, cfg.Admin.ClientCAFile)
+			if err != nil {
+				adminLn.Close()
+				return fmt.Errorf("could not load admin.client_ca_file: %w", err)
+			}
+		}
+		listeners = append(listeners, listener{adminServer, adminLn})
+	}
+
+	if cfg.TLS.RedirectAddress != "" {
+		redirectLn, err := net.Listen("tcp", cfg.TLS.RedirectAddress)
+		if err != nil {
+			return fmt.Errorf("could not listen on tls.redirect_address %s: %w", cfg.TLS.RedirectAddress, err)
+		}
+		redirect := s.httpServer(redirectToHTTPS(cfg.Server.Port), cfg.Server)
+		listeners = append(listeners, listener{redirect, redirectLn})
+	}
+
+	errc := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			if err := l.serve(); err != nil && err != http.ErrServerClosed {
+				errc <- err
+			}
+		}()
+	}
+	scheme := "http"
+	if certs != nil {
+		scheme = "https"
+	}
+	s.logger.Infof("Server running at %s://localhost:%s", scheme, port)
+	if s.admin != nil {
+		s.logger.Infof("Admin endpoints at %s", cfg.Admin.Address)
+	}
+	if cfg.TLS.RedirectAddress != "" {
+		s.logger.Infof("Redirecting HTTP on %s to HTTPS", cfg.TLS.RedirectAddress)
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case serveErr = <-errc:
+		s.logger.Error("Server failed", logger.Err(serveErr))
+	}
+
+	s.logger.Info("Shutting down server...")
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancelShutdown()
+	for _, l := range listeners {
+		err := l.srv.Shutdown(shutdownCtx)
+		if errors.Is(err, context.DeadlineExceeded) {
+			s.logger.Warn("Requests still running after server.shutdown_timeout, closing their connections",
+				"timeout", cfg.Server.ShutdownTimeout.String())
+			err = l.srv.Close()
+		}
+		if err != nil {
+			serveErr = errors.Join(serveErr, fmt.Errorf("server shutdown failed: %w", err))
+		}
+	}
+	if serveErr == nil {
+		s.logger.Info("Server gracefully stopped")
+	}
+	return serveErr
+}
+
+// listener pairs an http.Server with the listener it serves.
+type listener struct {
+	srv *http.Server
+	ln  net.Listener
+}
+
+// serve serves HTTPS if the server has a TLS config, plain HTTP otherwise.
+func (l listener) serve() error {
+	if l.srv.TLSConfig != nil {
+		return l.srv.ServeTLS(l.ln, "", "")
+	}
+	return l.srv.Serve(l.ln)
+}
+
+// httpServer returns an http.Server for h with the configured timeouts.
+func (s *Server) httpServer(h http.Handler, cfg config.ServerConfig) *http.Server {
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout.Duration,
+		ReadTimeout:       cfg.ReadTimeout.Duration,
+		WriteTimeout:      cfg.WriteTimeout.Duration,
+		IdleTimeout:       cfg.IdleTimeout.Duration,
+		ErrorLog:          slog.NewLogLogger(s.logger.Slog().Handler(), slog.LevelWarn),
+	}
+	srv.RegisterOnShutdown(s.stopStreams)
+	return srv
+}
+
+// listenAdmin opens the admin listener. A stale Unix socket left by an
+// unclean exit is removed first, and the new one is not world-accessible.
+func listenAdmin(cfg config.AdminConfig) (net.Listener, error) {
+	network, address := cfg.Listen()
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+	if err := os.Remove(address); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, 0660); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/api"
+	"github.com/whatcher1074/stockspotlight/internal/audit"
+	"github.com/whatcher1074/stockspotlight/internal/cache"
+	"github.com/whatcher1074/stockspotlight/internal/config"
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+)
+
+const (
+	testAPIKey     = "finnhub-test-api-key"
+	testAdminToken = "abcdefghijklmnopqrstu"
+)
+
+type stubProvider struct{}
+
+func (stubProvider) FetchAllData(context.Context, []string, int, string) ([]api.CombinedData, error) {
+	return nil, nil
+}
+
+func (stubProvider) Ping(context.Context) error { return nil }
+
+// withToken configures the admin token "ci".
+func withToken(c *config.Config) {
+	c.Admin.Auth.Tokens = []config.AdminToken{{Name: "ci", Token: testAdminToken}}
+}
+
+// newTestServer builds a Server from the default config, changed by modify.
+func newTestServer(t *testing.T, modify func(*config.Config)) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Provider.APIKey = testAPIKey
+	modify(&cfg)
+
+	log, err := logger.New(filepath.Join(dir, "app.log"), logger.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+	auditLog, err := audit.Open(filepath.Join(dir, "audit"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { auditLog.Close() })
+
+	return New(Options{
+		Config:    config.NewStore(filepath.Join(dir, "app.yaml"), &cfg),
+		Provider:  stubProvider{},
+		Cache:     cache.New(),
+		Logger:    log,
+		Audit:     auditLog,
+		Templates: &Templates{},
+		Started:   time.Now(),
+	})
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestAdminRouting(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*config.Config)
+		// separate reports whether the admin endpoints get a listener of
+		// their own.
+		separate bool
+		// anonymous and token are the statuses of /admin/config without
+		// and with the admin token.
+		anonymous, token int
+	}{
+		{
+			name:      "separate listener, no auth",
+			modify:    func(c *config.Config) {},
+			separate:  true,
+			anonymous: http.StatusOK,
+			token:     http.StatusOK,
+		},
+		{
+			name:      "separate listener with auth",
+			modify:    withToken,
+			separate:  true,
+			anonymous: http.StatusUnauthorized,
+			token:     http.StatusOK,
+		},
+		{
+			name:      "public port with auth",
+			modify:    func(c *config.Config) { c.Admin.Address = ""; withToken(c) },
+			anonymous: http.StatusUnauthorized,
+			token:     http.StatusOK,
+		},
+		{
+			// Rejected by Validate; not served even if that was skipped
+			name:      "public port without auth",
+			modify:    func(c *config.Config) { c.Admin.Address = "" },
+			anonymous: http.StatusNotFound,
+			token:     http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.modify)
+
+			admin := s.AdminRoutes()
+			if (admin != nil) != tt.separate {
+				t.Fatalf("AdminRoutes() = %v, want a separate handler: %v", admin, tt.separate)
+			}
+			if admin == nil {
+				admin = s.Routes()
+			} else {
+				for _, path := range []string{"/admin/config", "/logs/status", "/metrics"} {
+					if w := serve(s.Routes(), httptest.NewRequest(http.MethodGet, path, nil)); w.Code != http.StatusNotFound {
+						t.Errorf("public %s = %d, want 404", path, w.Code)
+					}
+				}
+			}
+
+			if w := serve(admin, httptest.NewRequest(http.MethodGet, "/admin/config", nil)); w.Code != tt.anonymous {
+				t.Errorf("/admin/config without credentials = %d, want %d", w.Code, tt.anonymous)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+			r.Header.Set("Authorization", "Bearer "+testAdminToken)
+			if w := serve(admin, r); w.Code != tt.token {
+				t.Errorf("/admin/config with token = %d, want %d", w.Code, tt.token)
+			}
+
+			// pprof and expvar only on the separate listener
+			wantDebug := http.StatusNotFound
+			if tt.separate {
+				wantDebug = http.StatusOK
+			}
+			r = httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			r.Header.Set("Authorization", "Bearer "+testAdminToken)
+			if w := serve(admin, r); w.Code != wantDebug {
+				t.Errorf("/debug/vars = %d, want %d", w.Code, wantDebug)
+			}
+			if w := serve(s.Routes(), httptest.NewRequest(http.MethodGet, "/healthz/live", nil)); w.Code != http.StatusOK {
+				t.Errorf("public /healthz/live = %d, want 200", w.Code)
+			}
+		})
+	}
+}
+
+func TestAdminActionsAreAudited(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(*config.Config)
+		prepare    func(*http.Request)
+		wantActor  string
+		wantRemote string
+	}{
+		{
+			name:   "token behind a trusted proxy",
+			modify: func(c *config.Config) { withToken(c); c.Server.TrustedProxies = []string{"192.0.2.0/24"} },
+			prepare: func(r *http.Request) {
+				r.RemoteAddr = "192.0.2.1:4000"
+				r.Header.Set("X-Forwarded-For", "203.0.113.7")
+				r.Header.Set("Authorization", "Bearer "+testAdminToken)
+			},
+			wantActor:  "token:ci",
+			wantRemote: "203.0.113.7",
+		},
+		{
+			name:   "client certificate",
+			modify: func(c *config.Config) {},
+			prepare: func(r *http.Request) {
+				r.RemoteAddr = "198.51.100.4:4000"
+				r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+					{Subject: pkix.Name{CommonName: "ops-laptop"}},
+				}}}
+			},
+			wantActor:  "cert:ops-laptop",
+			wantRemote: "198.51.100.4",
+		},
+		{
+			name:   "untrusted forwarded header",
+			modify: withToken,
+			prepare: func(r *http.Request) {
+				r.RemoteAddr = "198.51.100.4:4000"
+				r.Header.Set("X-Forwarded-For", "203.0.113.7")
+				r.Header.Set("Authorization", "Bearer "+testAdminToken)
+			},
+			wantActor:  "token:ci",
+			wantRemote: "198.51.100.4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.modify)
+			s.cache.Set("quote:AAPL", 1, time.Minute)
+
+			r := httptest.NewRequest(http.MethodPost, "/admin/cache?prefix=quote:", nil)
+			tt.prepare(r)
+			if w := serve(s.AdminRoutes(), r); w.Code != http.StatusOK {
+				t.Fatalf("POST /admin/cache = %d: %s", w.Code, w.Body)
+			}
+
+			got, err := s.audit.Query(audit.Filter{Action: "cache.clear"}, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Events) != 1 {
+				t.Fatalf("found %d cache.clear events, want 1", len(got.Events))
+			}
+			ev := got.Events[0]
+			if ev.Actor != tt.wantActor || ev.RemoteAddr != tt.wantRemote || ev.Outcome != audit.OutcomeSuccess {
+				t.Errorf("event = %+v, want actor %s from %s succeeding", ev, tt.wantActor, tt.wantRemote)
+			}
+			if ev.RequestID == "" {
+				t.Error("event has no request ID")
+			}
+		})
+	}
+}
+
+func TestAdminAuthFailureIsAudited(t *testing.T) {
+	s := newTestServer(t, withToken)
+	r := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+	r.SetBasicAuth("mallory", "guess")
+	if w := serve(s.AdminRoutes(), r); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+
+	got, err := s.audit.Query(audit.Filter{Action: "admin.auth", Outcome: audit.OutcomeDenied}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Events) != 1 || got.Events[0].Actor != "mallory" {
+		t.Errorf("denied events = %+v, want one claiming mallory", got.Events)
+	}
+}
+
+func TestAdminConfigRedactsSecrets(t *testing.T) {
+	s := newTestServer(t, func(c *config.Config) {
+		withToken(c)
+		c.Admin.Auth.Users = []config.AdminUser{{Username: "ops", PasswordHash: "$2a$10$secrethashsecrethashsecrethashsecrethashsecrethash"}}
+	})
+	r := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+	r.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := serve(s.AdminRoutes(), r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, secret := range []string{testAPIKey, testAdminToken, "secrethash"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("/admin/config leaks %q", secret)
+		}
+	}
+	if !strings.Contains(body, "[REDACTED]") || !strings.Contains(body, `"ci"`) {
+		t.Errorf("/admin/config = %s, want token names shown and secrets redacted", body)
+	}
+}
@@ -0,0 +1,54 @@
+// File: internal/server/templates.go
+package server
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"text/template"
+
+	"github.com/whatcher1074/stockspotlight/internal/tracing"
+)
+
+// Templates holds the dashboard page and the HTMX fragments of its widgets.
+type Templates struct {
+	Index          *template.Template
+	StockTable     *template.Template
+	GainersTable   *template.Template
+	LosersTable    *template.Template
+	CompanyProfile *template.Template
+	NewsFeed       *template.Template
+}
+
+// LoadTemplates parses the templates from dir.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{}
+	for _, f := range []struct {
+		name string
+		dst  **template.Template
+	}{
+		{"index.html", &t.Index},
+		{"stock_table.html", &t.StockTable},
+		{"gainers_table.html", &t.GainersTable},
+		{"losers_table.html", &t.LosersTable},
+		{"company_profile.html", &t.CompanyProfile},
+		{"news_feed.html", &t.NewsFeed},
+	} {
+		tmpl, err := template.ParseFiles(filepath.Join(dir, f.name))
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = tmpl
+	}
+	return t, nil
+}
+
+var tracer = tracing.Tracer("internal/server")
+
+// executeTemplate renders tmpl to w inside a span named after the template.
+func executeTemplate(ctx context.Context, w io.Writer, tmpl *template.Template, data interface{}) error {
+	_, span := tracer.Start(ctx, "template "+tmpl.Name())
+	err := tmpl.Execute(w, data)
+	tracing.End(span, err)
+	return err
+}
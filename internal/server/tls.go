@@ -0,0 +1,171 @@
+// File: internal/server/tls.go
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/config"
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+)
+
+// certReloader serves a certificate and key pair from disk, reloading it
+// when either file changes so renewed certificates apply without a restart.
+type certReloader struct {
+	certFile, keyFile string
+	cert              atomic.Pointer[tls.Certificate]
+}
+
+// newCertReloader loads the pair, failing if it cannot be used.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.cert.Store(&cert)
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load(), nil
+}
+
+// Watch reloads the pair whenever either file's modification time or size
+// changes, checking every interval, until ctx is cancelled. A pair that fails
+// to load is logged and the current certificate kept; the check repeats on
+// the next change, so a cert written before its key is picked up once both
+// are in place.
+func (c *certReloader) Watch(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := c.stat()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cur := c.stat()
+			if cur == last {
+				continue
+			}
+			last = cur
+			if err := c.load(); err != nil {
+				log.Error("Failed to reload TLS certificate, keeping the current one",
+					"cert_file", c.certFile, logger.Err(err))
+				continue
+			}
+			log.Info("Reloaded TLS certificate", "cert_file", c.certFile, "expires", c.expiry())
+		}
+	}
+}
+
+// fileState is what Watch compares to notice a changed file.
+type fileState struct {
+	mod  time.Time
+	size int64
+}
+
+func (c *certReloader) stat() [2]fileState {
+	var states [2]fileState
+	for i, path := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			states[i].size = -1
+			continue
+		}
+		states[i] = fileState{info.ModTime(), info.Size()}
+	}
+	return states
+}
+
+// expiry returns when the current certificate expires, or the zero time if
+// it cannot be parsed.
+func (c *certReloader) expiry() time.Time {
+	cert := c.cert.Load()
+	if len(cert.Certificate) == 0 {
+		return time.Time{}
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return time.Time{}
+	}
+	return leaf.NotAfter
+}
+
+// tlsConfig returns the TLS settings for a listener serving the certificate
+// from certs. HTTP/2 is negotiated by http.Server.ServeTLS.
+func tlsConfig(certs *certReloader) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+}
+
+// adminTLSConfig adds client certificate verification against the CAs in
+// caFile to the listener settings, if caFile is set.
+func adminTLSConfig(certs *certReloader, caFile string) (*tls.Config, error) {
+	cfg := tlsConfig(certs)
+	if caFile == "" {
+		return cfg, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in " + caFile)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return cfg, nil
+}
+
+// redirectToHTTPS answers plain HTTP requests with a permanent redirect to
+// the same URL over HTTPS on port.
+func redirectToHTTPS(port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// loadCertificates starts the certificate reloader for cfg.TLS, or returns
+// nil if TLS is not configured.
+func (s *Server) loadCertificates(ctx context.Context, cfg config.TLSConfig) (*certReloader, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	certs, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load TLS certificate: %w", err)
+	}
+	s.logger.Info("Loaded TLS certificate", "cert_file", cfg.CertFile, "expires", certs.expiry())
+	go certs.Watch(ctx, cfg.ReloadInterval.Duration, s.logger)
+	return certs, nil
+}
@@ -0,0 +1,248 @@
+// File: internal/server/widgets.go
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"text/template"
+	"time"
+
+	"github.com/whatcher1074/stockspotlight/internal/api"
+	"github.com/whatcher1074/stockspotlight/internal/config"
+	"github.com/whatcher1074/stockspotlight/internal/health"
+	"github.com/whatcher1074/stockspotlight/internal/logger"
+	"github.com/whatcher1074/stockspotlight/internal/middleware"
+)
+
+// registerPublic adds the dashboard, its data fragments, static assets and
+// health checks to mux.
+func (s *Server) registerPublic(mux *http.ServeMux, cfg *config.Config, trusted []netip.Prefix) {
+	// Static assets (Bootstrap, etc.)
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(s.staticDir))))
+
+	// Health check
+	status := &health.Reporter{
+		Started:   s.started,
+		Provider:  func() string { return s.config.Current().Provider.Name },
+		CacheSize: s.cache.Len,
+		Fetches:   s.fetches,
+	}
+	mux.HandleFunc("/healthz", status.Handler)
+	mux.HandleFunc("/version", health.VersionHandler)
+	mux.HandleFunc("/healthz/live", health.LiveHandler)
+	mux.HandleFunc("/healthz/ready", s.readiness(cfg).ReadyHandler)
+
+	// Stock screener endpoints, limited per client since cache misses reach
+	// the provider
+	limit := s.clientRateLimit(cfg, trusted)
+	mux.Handle("/data/most-active", limit(s.screener("most_active", s.templates.StockTable, "screener:most_active")))
+	mux.Handle("/data/gainers", limit(s.screener("gainers", s.templates.GainersTable, "screener:gainers")))
+	mux.Handle("/data/losers", limit(s.screener("losers", s.templates.LosersTable, "screener:losers")))
+
+	mux.Handle("/data/profile", limit(http.HandlerFunc(s.handleProfile)))
+	mux.Handle("/data/news", limit(http.HandlerFunc(s.handleNews)))
+
+	// UI entry point
+	mux.HandleFunc("/", s.handleIndex)
+}
+
+// clientRateLimit builds the per-client limit on the data endpoints. The
+// limits follow config reloads; tokens and trusted proxies are read once.
+func (s *Server) clientRateLimit(cfg *config.Config, trusted []netip.Prefix) middleware.Middleware {
+	tokens := make(map[string]string, len(cfg.Admin.Auth.Tokens))
+	for _, t := range cfg.Admin.Auth.Tokens {
+		tokens[t.Token.Value()] = t.Name
+	}
+	return middleware.RateLimit(middleware.RateLimitOptions{
+		Limits: func() middleware.RateLimits {
+			c := s.config.Current().RateLimit.Clients
+			return middleware.RateLimits{PerMinute: c.RequestsPerMinute, Burst: c.Burst}
+		},
+		TrustedProxies: trusted,
+		Tokens:         tokens,
+		OnLimited: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			renderError(w, r, http.StatusTooManyRequests, "Too many requests, please slow down and try again shortly")
+		}),
+	})
+}
+
+// handleIndex renders the dashboard. Only the root renders it, so admin
+// paths moved to the admin listener are not found here.
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	reqLog := logger.FromContext(r.Context())
+	reqLog.Debug("Serving UI /")
+	err := executeTemplate(r.Context(), w, s.templates.Index, s.config.Current().Dashboard)
+	if err != nil {
+		reqLog.Error("Index template render failed", logger.Err(err))
+	}
+}
+
+// screener returns the handler for a screener widget (most active, gainers,
+// losers), which renders tmpl with the rows for signal.
+func (s *Server) screener(signal string, tmpl *template.Template, cacheKey string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLog := logger.FromContext(r.Context())
+		reqLog.Debug("Request received", logger.Signal(signal))
+		cfg := s.config.Current()
+		now := time.Now().Format("15:04:05")
+
+		cachedData, found := s.cache.Get(r.Context(), cacheKey)
+		var displayData []api.CombinedData
+
+		if found {
+			if d, ok := cachedData.([]api.CombinedData); ok {
+				displayData = d
+				reqLog.Debug("Using cached data", logger.Signal(signal), logger.CacheHit(true), "items", len(d))
+			} else {
+				reqLog.Warn("Cached data type mismatch", logger.Signal(signal))
+				found = false
+			}
+		}
+
+		// If data not found in cache or expired, fetch it
+		if !found {
+			reqLog.Debug("Fetching fresh data", logger.Signal(signal), logger.CacheHit(false))
+			start := time.Now()
+			data, err := s.provider.FetchAllData(r.Context(), cfg.Dashboard.Tickers, cfg.Dashboard.TickerLimit, signal)
+			if err != nil {
+				reqLog.Error("Failed to fetch data", logger.Signal(signal), logger.Provider(cfg.Provider.Name),
+					logger.Duration(time.Since(start)), logger.Err(err))
+				pageData := map[string]interface{}{
+					"HasData":   false,
+					"ErrorMsg":  fmt.Sprintf("Failed to load %s data: %v", signal, err),
+					"Timestamp": now,
+				}
+				executeTemplate(r.Context(), w, tmpl, pageData)
+				return
+			}
+			s.cache.Set(cacheKey, data, cfg.Cache.TTL.Duration)
+			s.fetches.Record(signal)
+			displayData = data
+			reqLog.Info("Fetched fresh data", logger.Signal(signal), logger.Provider(cfg.Provider.Name),
+				logger.CacheHit(false), logger.Duration(time.Since(start)), "items", len(data), "ttl", cfg.Cache.TTL.String())
+		}
+
+		pageData := map[string]interface{}{
+			"Data":      displayData,
+			"HasData":   len(displayData) > 0,
+			"ErrorMsg":  "",
+			"Timestamp": now,
+		}
+
+		err := executeTemplate(r.Context(), w, tmpl, pageData)
+		if err != nil {
+			reqLog.Error("Template render failed", logger.Signal(signal), logger.Err(err))
+		}
+	}
+}
+
+// handleProfile renders the company profile widget for ?symbol= (AAPL by
+// default).
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		symbol = "AAPL" // Default to Apple
+	}
+
+	reqLog.Debug("Request received for company profile", logger.Symbol(symbol))
+	cfg := s.config.Current()
+	now := time.Now().Format("15:04:05")
+	cacheKey := fmt.Sprintf("profile:%s", symbol)
+
+	cachedData, found := s.cache.Get(r.Context(), cacheKey)
+	var profileData map[string]interface{}
+
+	if found {
+		if d, ok := cachedData.(map[string]interface{}); ok {
+			profileData = d
+			reqLog.Debug("Using cached profile data", logger.Symbol(symbol), logger.CacheHit(true))
+		} else {
+			found = false
+		}
+	}
+
+	if !found {
+		reqLog.Debug("Fetching fresh profile data", logger.Symbol(symbol), logger.CacheHit(false))
+		profileData = mockProfile(symbol)
+		s.cache.Set(cacheKey, profileData, cfg.Cache.ProfileTTL.Duration)
+		s.fetches.Record("profile")
+		reqLog.Debug("Cached profile data", logger.Symbol(symbol), "ttl", cfg.Cache.ProfileTTL.String())
+	}
+
+	// Determine exchange based on symbol (mock logic)
+	exchange := "NASDAQ"
+	if len(symbol) > 0 && (symbol[0] >= 'A' && symbol[0] <= 'M') {
+		exchange = "NYSE"
+	}
+
+	pageData := map[string]interface{}{
+		"Data":      profileData,
+		"HasData":   profileData != nil,
+		"ErrorMsg":  "",
+		"Timestamp": now,
+		"Name":      profileData["Name"],
+		"Ticker":    symbol,
+		"Exchange":  exchange,
+		"Industry":  profileData["Industry"],
+		"WebURL":    profileData["WebURL"],
+		"Logo":      profileData["Logo"],
+	}
+
+	err := executeTemplate(r.Context(), w, s.templates.CompanyProfile, pageData)
+	if err != nil {
+		reqLog.Error("Template render failed for profile", logger.Symbol(symbol), logger.Err(err))
+	}
+}
+
+// handleNews renders the news feed widget for ?category= (general by
+// default).
+func (s *Server) handleNews(w http.ResponseWriter, r *http.Request) {
+	reqLog := logger.FromContext(r.Context())
+	category := r.URL.Query().Get("category")
+	if category == "" {
+		category = "general"
+	}
+
+	reqLog.Debug("Request received for news", "category", category)
+	cfg := s.config.Current()
+	now := time.Now().Format("15:04:05")
+	cacheKey := fmt.Sprintf("news:%s", category)
+
+	cachedData, found := s.cache.Get(r.Context(), cacheKey)
+	var displayData []map[string]interface{}
+
+	if found {
+		if d, ok := cachedData.([]map[string]interface{}); ok {
+			displayData = d
+			reqLog.Debug("Using cached news data", "category", category, logger.CacheHit(true), "articles", len(d))
+		} else {
+			found = false
+		}
+	}
+
+	if !found {
+		reqLog.Debug("Fetching fresh news data", "category", category, logger.CacheHit(false))
+		displayData = mockNews(category, time.Now())
+		s.cache.Set(cacheKey, displayData, cfg.Cache.NewsTTL.Duration)
+		s.fetches.Record("news")
+		reqLog.Debug("Cached news articles", "category", category, "articles", len(displayData), "ttl", cfg.Cache.NewsTTL.String())
+	}
+
+	pageData := map[string]interface{}{
+		"Data":      displayData,
+		"HasData":   len(displayData) > 0,
+		"ErrorMsg":  "",
+		"Timestamp": now,
+	}
+
+	err := executeTemplate(r.Context(), w, s.templates.NewsFeed, pageData)
+	if err != nil {
+		reqLog.Error("Template render failed for news", "category", category, logger.Err(err))
+	}
+}
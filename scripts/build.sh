@@ -0,0 +1,13 @@
+#!/bin/bash
+# File: scripts/build.sh
+# Builds ./stockspotlight with version, commit and build time injected
+
+set -euo pipefail
+
+pkg=github.com/whatcher1074/stockspotlight/internal/buildinfo
+version=${VERSION:-$(git describe --tags --always --dirty 2>/dev/null || echo dev)}
+commit=$(git rev-parse HEAD 2>/dev/null || echo unknown)
+built=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+
+go build -ldflags "-X ${pkg}.Version=${version} -X ${pkg}.Commit=${commit} -X ${pkg}.BuildTime=${built}" \
+  -o "${OUTPUT:-stockspotlight}" ./cmd